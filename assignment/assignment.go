@@ -0,0 +1,127 @@
+package assignment
+
+import (
+	"fmt"
+
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ===============================
+// Strategi pembagian surat
+// ===============================
+
+const (
+	StrategyRoundRobin  = "round_robin"  // bergiliran sesuai urutan reviewer
+	StrategyLeastLoaded = "least_loaded" // reviewer dengan antrean surat paling sedikit
+	StrategyManual      = "manual"       // tidak otomatis, admin yang menugaskan
+)
+
+// Validate memeriksa strategi pembagian milik jenis surat
+func Validate(strategy string) error {
+	switch strategy {
+	case "", StrategyRoundRobin, StrategyLeastLoaded, StrategyManual:
+		return nil
+	}
+	return fmt.Errorf("Strategi pembagian '%s' tidak dikenali (round_robin/least_loaded/manual)", strategy)
+}
+
+// Candidates mengembalikan ID reviewer yang bisa ditugaskan untuk jenis surat,
+// urut berdasarkan ID. Anggota pool yang sudah bukan reviewer diabaikan;
+// kalau pool kosong, semua reviewer menjadi kandidat.
+func Candidates(tx *gorm.DB, typeID uint) ([]uint, error) {
+	var pooled int64
+	if err := tx.Model(&models.LetterTypeReviewer{}).Where("type_id = ?", typeID).Count(&pooled).Error; err != nil {
+		return nil, err
+	}
+
+	q := tx.Model(&models.User{}).
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("roles.name = ?", policy.RoleReviewer)
+	if pooled > 0 {
+		q = q.Where("users.id IN (?)",
+			tx.Model(&models.LetterTypeReviewer{}).Select("user_id").Where("type_id = ?", typeID))
+	}
+
+	var ids []uint
+	err := q.Order("users.id").Pluck("users.id", &ids).Error
+	return ids, err
+}
+
+// Workload menghitung jumlah surat yang masih menunggu keputusan per reviewer
+func Workload(tx *gorm.DB, reviewerIDs []uint) (map[uint]int64, error) {
+	load := make(map[uint]int64, len(reviewerIDs))
+	if len(reviewerIDs) == 0 {
+		return load, nil
+	}
+
+	var rows []struct {
+		AssignedReviewerID uint
+		Total              int64
+	}
+	err := tx.Model(&models.Letter{}).
+		Select("assigned_reviewer_id, COUNT(*) AS total").
+		Where("assigned_reviewer_id IN ?", reviewerIDs).
+		Where("status IN ?", []string{workflow.StatusSubmitted, workflow.StatusInReview}).
+		Group("assigned_reviewer_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		load[r.AssignedReviewerID] = r.Total
+	}
+	return load, nil
+}
+
+// Pick memilih reviewer untuk surat baru dari jenis surat lt di dalam transaksi tx.
+// Baris jenis surat dikunci (SELECT ... FOR UPDATE) supaya pengajuan bersamaan
+// tidak mendapat giliran yang sama. Mengembalikan nil kalau strategi manual
+// atau tidak ada reviewer yang tersedia.
+func Pick(tx *gorm.DB, lt models.LetterType) (*uint, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lt, lt.ID).Error; err != nil {
+		return nil, err
+	}
+	if lt.AssignmentStrategy == StrategyManual {
+		return nil, nil
+	}
+
+	candidates, err := Candidates(tx, lt.ID)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	var picked uint
+	if lt.AssignmentStrategy == StrategyLeastLoaded {
+		load, err := Workload(tx, candidates)
+		if err != nil {
+			return nil, err
+		}
+		picked = candidates[0]
+		for _, id := range candidates[1:] {
+			if load[id] < load[picked] {
+				picked = id
+			}
+		}
+	} else {
+		// round robin: reviewer berikutnya setelah yang terakhir ditugaskan
+		picked = candidates[0]
+		if lt.LastAssignedReviewerID != nil {
+			for _, id := range candidates {
+				if id > *lt.LastAssignedReviewerID {
+					picked = id
+					break
+				}
+			}
+		}
+	}
+
+	if err := tx.Model(&lt).Update("last_assigned_reviewer_id", picked).Error; err != nil {
+		return nil, err
+	}
+	return &picked, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"sanbercode-golang-batch-70-final-project/models"
 
@@ -27,8 +28,75 @@ func ConnectDB() {
 		log.Fatal("Failed to connect database:", err)
 	}
 
+	// kode nomor surat harus terisi & unik sebelum index uniknya dibuat AutoMigrate:
+	// kolom ditambahkan dulu (tanpa index), lalu kode lama yang kosong / kembar diisi
+	if db.Migrator().HasTable(&models.LetterType{}) {
+		if !db.Migrator().HasColumn(&models.LetterType{}, "NumberCode") {
+			if err := db.Migrator().AddColumn(&models.LetterType{}, "NumberCode"); err != nil {
+				log.Println("Gagal menambah kolom kode nomor jenis surat:", err)
+			}
+		}
+		backfillNumberCodes(db)
+	}
+
 	// migrate otomatis
-	db.AutoMigrate(&models.Role{}, &models.User{}, &models.LetterType{}, &models.Letter{}, &models.Setting{})
+	db.AutoMigrate(&models.Role{}, &models.User{}, &models.LetterType{}, &models.Letter{}, &models.Setting{}, &models.LetterStatusHistory{}, &models.LetterNumberSequence{}, &models.Attachment{}, &models.LetterRevision{}, &models.ApprovalStep{}, &models.LetterApproval{}, &models.LetterTypeReviewer{}, &models.LetterComment{}, &models.NotificationOutbox{}, &models.NotificationDelivery{}, &models.NotificationOutboxLetter{})
+
+	// status lama "pending" sekarang menjadi "submitted"
+	db.Model(&models.Letter{}).Where("status = ?", "pending").Update("status", "submitted")
 
 	DB = db
 }
+
+// backfillNumberCodes memberi kode nomor pada jenis surat lama yang kodenya kosong
+// atau sama dengan jenis surat sebelumnya, misal "JS3" atau "SK3" (3 = ID jenis surat)
+func backfillNumberCodes(db *gorm.DB) {
+	var types []models.LetterType
+	if err := db.Select("id", "number_code").Order("id").Find(&types).Error; err != nil {
+		log.Println("Gagal membaca kode nomor jenis surat:", err)
+		return
+	}
+
+	// semua kode yang sudah ada dianggap terpakai, termasuk milik baris setelahnya
+	taken := map[string]bool{}
+	for _, lt := range types {
+		taken[strings.TrimSpace(lt.NumberCode)] = true
+	}
+
+	kept := map[string]bool{}
+	for _, lt := range types {
+		code := strings.TrimSpace(lt.NumberCode)
+		if code != "" && !kept[code] {
+			kept[code] = true
+			continue
+		}
+
+		code = uniqueNumberCode(code, lt.ID, taken)
+		taken[code] = true
+		kept[code] = true
+		if err := db.Model(&models.LetterType{}).Where("id = ?", lt.ID).Update("number_code", code).Error; err != nil {
+			log.Println("Gagal mengisi kode nomor jenis surat:", err)
+		}
+	}
+}
+
+// uniqueNumberCode menyusun kode dari base + ID jenis surat (maks 20 karakter, sesuai kolom)
+// yang belum dipakai; kalau tetap bentrok ditambah akhiran -2, -3, dst.
+func uniqueNumberCode(base string, id uint, taken map[string]bool) string {
+	if base == "" {
+		base = "JS"
+	}
+	for n := 1; ; n++ {
+		suffix := fmt.Sprintf("%d", id)
+		if n > 1 {
+			suffix = fmt.Sprintf("%d-%d", id, n)
+		}
+		prefix := base
+		if len(prefix)+len(suffix) > 20 {
+			prefix = prefix[:20-len(suffix)]
+		}
+		if code := prefix + suffix; !taken[code] {
+			return code
+		}
+	}
+}
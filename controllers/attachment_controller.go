@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ===============================
+// Aturan upload lampiran
+// ===============================
+
+// maxAttachmentsPerUpload adalah jumlah file maksimal dalam satu request
+const maxAttachmentsPerUpload = 5
+
+// allowedAttachmentTypes berisi MIME yang boleh diupload beserta ekstensinya
+var allowedAttachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// maxAttachmentSize membaca batas ukuran file dari env MAX_ATTACHMENT_SIZE_MB (default 5 MB)
+func maxAttachmentSize() int64 {
+	if mb, err := strconv.ParseInt(os.Getenv("MAX_ATTACHMENT_SIZE_MB"), 10, 64); err == nil && mb > 0 {
+		return mb << 20
+	}
+	return 5 << 20
+}
+
+// attachmentUpload adalah file yang sudah lolos validasi dan siap disimpan
+type attachmentUpload struct {
+	header      *multipart.FileHeader
+	contentType string
+}
+
+// checkAttachments memvalidasi jumlah, ukuran dan MIME (dari isi file, bukan dari nama)
+func checkAttachments(files []*multipart.FileHeader) ([]attachmentUpload, error) {
+	if len(files) > maxAttachmentsPerUpload {
+		return nil, fmt.Errorf("Maksimal %d lampiran per upload", maxAttachmentsPerUpload)
+	}
+
+	limit := maxAttachmentSize()
+	uploads := make([]attachmentUpload, 0, len(files))
+	for _, fh := range files {
+		if fh.Size > limit {
+			return nil, fmt.Errorf("File %s melebihi batas %d MB", fh.Filename, limit>>20)
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("File %s tidak bisa dibaca", fh.Filename)
+		}
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		f.Close()
+
+		contentType := http.DetectContentType(head[:n])
+		if _, ok := allowedAttachmentTypes[contentType]; !ok {
+			return nil, fmt.Errorf("Tipe file %s (%s) tidak diizinkan, hanya PDF/JPG/PNG", fh.Filename, contentType)
+		}
+		uploads = append(uploads, attachmentUpload{header: fh, contentType: contentType})
+	}
+	return uploads, nil
+}
+
+// storeAttachments mengupload file ke storage dan mencatatnya di tabel attachments.
+// Kalau ada yang gagal, file yang sudah terupload dihapus lagi.
+func storeAttachments(ctx context.Context, tx *gorm.DB, letterID, uploaderID uint, uploads []attachmentUpload) ([]models.Attachment, error) {
+	var saved []models.Attachment
+	cleanup := func() {
+		deleteAttachmentFiles(saved)
+	}
+
+	for _, up := range uploads {
+		key, err := newAttachmentKey(letterID, allowedAttachmentTypes[up.contentType])
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		f, err := up.header.Open()
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		err = storage.Files.Put(ctx, key, f, up.header.Size, up.contentType)
+		f.Close()
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		attachment := models.Attachment{
+			LetterID:    letterID,
+			FileName:    filepath.Base(up.header.Filename),
+			ContentType: up.contentType,
+			Size:        up.header.Size,
+			StorageKey:  key,
+			UploadedBy:  uploaderID,
+		}
+		saved = append(saved, attachment)
+		if err := tx.Create(&saved[len(saved)-1]).Error; err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+	return saved, nil
+}
+
+// deleteAttachmentFiles menghapus file lampiran dari storage, dipakai saat transaksi
+// yang menyimpan lampiran gagal / di-rollback supaya tidak ada file yatim
+func deleteAttachmentFiles(attachments []models.Attachment) {
+	for _, a := range attachments {
+		if err := storage.Files.Delete(context.Background(), a.StorageKey); err != nil {
+			log.Println("Gagal menghapus file lampiran:", err)
+		}
+	}
+}
+
+// newAttachmentKey membuat key acak supaya nama file asli tidak dipakai di storage
+func newAttachmentKey(letterID uint, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("letters/%d/%s%s", letterID, hex.EncodeToString(b), ext), nil
+}
+
+// multipartFiles mengambil file dari field "attachments" kalau request multipart
+func multipartFiles(c *gin.Context) []*multipart.FileHeader {
+	form, err := c.MultipartForm()
+	if err != nil || form == nil {
+		return nil
+	}
+	return form.File["attachments"]
+}
+
+// ===============================
+// Upload Attachment
+// ===============================
+
+// UploadAttachments godoc
+// @Summary Upload letter attachments
+// @Description Tambah lampiran (PDF/JPG/PNG) ke surat yang belum diputuskan. Pemilik surat & admin.
+// @Tags Attachments
+// @Accept mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Param attachments formData file true "File lampiran (boleh lebih dari satu)"
+// @Success 201 {array} models.Attachment
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/attachments [post]
+func UploadAttachments(c *gin.Context) {
+	by := currentActor(c)
+
+	var letter models.Letter
+	if err := config.DB.First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+
+	if !authorizeLetter(c, policy.ActionAttach, &letter) {
+		return
+	}
+
+	files := multipartFiles(c)
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Field 'attachments' wajib berisi minimal satu file"})
+		return
+	}
+	uploads, err := checkAttachments(files)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var saved []models.Attachment
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		saved, err = storeAttachments(c.Request.Context(), tx, letter.ID, by.ID, uploads)
+		return err
+	})
+	if err != nil {
+		deleteAttachmentFiles(saved)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan lampiran"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, saved)
+}
+
+// ===============================
+// List & Download Attachment
+// ===============================
+
+// GetAttachments godoc
+// @Summary List letter attachments
+// @Description Daftar lampiran surat. User hanya bisa melihat lampiran surat miliknya.
+// @Tags Attachments
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Success 200 {array} models.Attachment
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/attachments [get]
+func GetAttachments(c *gin.Context) {
+	var letter models.Letter
+	if err := config.DB.First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+	if !authorizeLetter(c, policy.ActionView, &letter) {
+		return
+	}
+
+	var attachments []models.Attachment
+	config.DB.Where("letter_id = ?", letter.ID).Order("id").Find(&attachments)
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment godoc
+// @Summary Download letter attachment
+// @Description Unduh file lampiran surat. User hanya bisa mengunduh lampiran surat miliknya.
+// @Tags Attachments
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Param attachment_id path int true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/attachments/{attachment_id} [get]
+func DownloadAttachment(c *gin.Context) {
+	var letter models.Letter
+	if err := config.DB.First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+	if !authorizeLetter(c, policy.ActionView, &letter) {
+		return
+	}
+
+	var attachment models.Attachment
+	if err := config.DB.Where("letter_id = ?", letter.ID).
+		First(&attachment, c.Param("attachment_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	file, err := storage.Files.Get(c.Request.Context(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File lampiran tidak ditemukan di storage"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca lampiran"})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+	})
+}
+
+// deleteLetterAttachments menghapus data lampiran di dalam transaksi dan
+// mengembalikan key storage yang perlu dihapus setelah transaksi sukses
+func deleteLetterAttachments(tx *gorm.DB, letterID uint) ([]string, error) {
+	var attachments []models.Attachment
+	if err := tx.Where("letter_id = ?", letterID).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("letter_id = ?", letterID).Delete(&models.Attachment{}).Error; err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		keys = append(keys, a.StorageKey)
+	}
+	return keys, nil
+}
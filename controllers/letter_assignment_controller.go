@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"sanbercode-golang-batch-70-final-project/assignment"
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LetterAssignInput digunakan admin untuk menugaskan ulang surat
+type LetterAssignInput struct {
+	ReviewerID uint `json:"reviewer_id" binding:"required" example:"2"`
+}
+
+// LetterTypeReviewersInput berisi anggota pool reviewer jenis surat.
+// Array kosong = surat dibagikan ke semua reviewer.
+type LetterTypeReviewersInput struct {
+	UserIDs []uint `json:"user_ids" example:"2,3"`
+}
+
+// ReviewerLoad adalah reviewer beserta jumlah surat yang masih menunggu keputusannya
+type ReviewerLoad struct {
+	UserID  uint   `json:"user_id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Pending int64  `json:"pending"`
+}
+
+// LetterTypeReviewersResponse adalah pool reviewer & strategi pembagian jenis surat
+type LetterTypeReviewersResponse struct {
+	TypeID             uint           `json:"type_id"`
+	AssignmentStrategy string         `json:"assignment_strategy"`
+	UsesPool           bool           `json:"uses_pool"` // false = semua reviewer jadi kandidat
+	Reviewers          []ReviewerLoad `json:"reviewers"`
+}
+
+// assignReviewer menugaskan surat yang baru dikirim ke reviewer sesuai strategi jenis suratnya.
+// Surat yang sudah punya reviewer (misal diajukan ulang) tetap ke reviewer yang sama.
+func assignReviewer(tx *gorm.DB, letter *models.Letter) error {
+	if letter.AssignedReviewerID != nil {
+		return nil
+	}
+
+	reviewerID, err := assignment.Pick(tx, models.LetterType{ID: letter.TypeID})
+	if err != nil || reviewerID == nil {
+		return err
+	}
+	letter.AssignedReviewerID = reviewerID
+	return tx.Model(letter).Update("assigned_reviewer_id", reviewerID).Error
+}
+
+// findReviewer memastikan user ada dan ber-role reviewer
+func findReviewer(id uint) (models.User, error) {
+	var user models.User
+	if err := config.DB.Preload("Role").First(&user, id).Error; err != nil {
+		return user, fmt.Errorf("User %d tidak ditemukan", id)
+	}
+	if user.Role.Name != policy.RoleReviewer {
+		return user, fmt.Errorf("User %d bukan reviewer", id)
+	}
+	return user, nil
+}
+
+// ===============================
+// Assign Letter
+// ===============================
+
+// AssignLetter godoc
+// @Summary Reassign a letter to a reviewer
+// @Description Tugaskan ulang surat yang menunggu keputusan ke reviewer lain (admin only). Hanya reviewer baru yang diberi tahu.
+// @Tags Letters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Param request body LetterAssignInput true "Reviewer tujuan"
+// @Success 200 {object} models.Letter
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /letters/{id}/assignee [put]
+func AssignLetter(c *gin.Context) {
+	by := currentActor(c)
+
+	var letter models.Letter
+	if err := config.DB.First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+	if !authorizeLetter(c, policy.ActionAssign, &letter) {
+		return
+	}
+
+	var input LetterAssignInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !workflow.IsPending(letter.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Hanya surat yang menunggu keputusan yang bisa ditugaskan"})
+		return
+	}
+	reviewer, err := findReviewer(input.ReviewerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if letter.AssignedReviewerID != nil && *letter.AssignedReviewerID == reviewer.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Surat sudah ditugaskan ke reviewer tersebut"})
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockLetter(tx, letter.ID, letter.Status); err != nil {
+			return err
+		}
+		if err := tx.Model(&letter).Update("assigned_reviewer_id", reviewer.ID).Error; err != nil {
+			return err
+		}
+		// Penugasan ulang dicatat di riwayat tanpa perpindahan status
+		changes := []statusChange{{From: letter.Status, To: letter.Status}}
+		if err := recordLetterHistory(tx, letter.ID, by, changes, fmt.Sprintf("Ditugaskan ke reviewer %s", reviewer.Name)); err != nil {
+			return err
+		}
+		notice, err := noticeLetter(tx, letter.ID)
+		if err != nil {
+			return err
+		}
+		return notifyLetterAssigned(tx, notice)
+	})
+	if err != nil {
+		respondTxError(c, err, "Gagal menugaskan surat")
+		return
+	}
+
+	config.DB.Preload("User.Role").Preload("LetterType").Preload("AssignedReviewer").First(&letter, letter.ID)
+
+	c.JSON(http.StatusOK, letter)
+}
+
+// ===============================
+// Reviewer Pool per Letter Type
+// ===============================
+
+// GetLetterTypeReviewers godoc
+// @Summary Get reviewer pool of a letter type
+// @Description Ambil strategi pembagian & kandidat reviewer jenis surat beserta beban kerjanya (admin only)
+// @Tags Letter Types
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter Type ID"
+// @Success 200 {object} LetterTypeReviewersResponse
+// @Failure 404 {object} map[string]string
+// @Router /letter_types/{id}/reviewers [get]
+func GetLetterTypeReviewers(c *gin.Context) {
+	var lt models.LetterType
+	if err := config.DB.First(&lt, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter Type not found"})
+		return
+	}
+	respondLetterTypeReviewers(c, lt)
+}
+
+// SetLetterTypeReviewers godoc
+// @Summary Replace reviewer pool of a letter type
+// @Description Ganti seluruh anggota pool reviewer jenis surat (admin only). Kosongkan user_ids agar semua reviewer jadi kandidat.
+// @Tags Letter Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter Type ID"
+// @Param request body LetterTypeReviewersInput true "Anggota pool reviewer"
+// @Success 200 {object} LetterTypeReviewersResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /letter_types/{id}/reviewers [put]
+func SetLetterTypeReviewers(c *gin.Context) {
+	var lt models.LetterType
+	if err := config.DB.First(&lt, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter Type not found"})
+		return
+	}
+
+	var input LetterTypeReviewersInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pool := make([]models.LetterTypeReviewer, 0, len(input.UserIDs))
+	seen := map[uint]bool{}
+	for _, id := range input.UserIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := findReviewer(id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		pool = append(pool, models.LetterTypeReviewer{TypeID: lt.ID, UserID: id})
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("type_id = ?", lt.ID).Delete(&models.LetterTypeReviewer{}).Error; err != nil {
+			return err
+		}
+		if len(pool) > 0 {
+			return tx.Create(&pool).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan pool reviewer"})
+		return
+	}
+
+	respondLetterTypeReviewers(c, lt)
+}
+
+// respondLetterTypeReviewers mengirim kandidat reviewer jenis surat beserta beban kerjanya
+func respondLetterTypeReviewers(c *gin.Context, lt models.LetterType) {
+	var pooled int64
+	config.DB.Model(&models.LetterTypeReviewer{}).Where("type_id = ?", lt.ID).Count(&pooled)
+
+	ids, err := assignment.Candidates(config.DB, lt.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil reviewer"})
+		return
+	}
+	load, err := assignment.Workload(config.DB, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung beban reviewer"})
+		return
+	}
+
+	var users []models.User
+	if len(ids) > 0 {
+		config.DB.Where("id IN ?", ids).Order("id").Find(&users)
+	}
+	reviewers := make([]ReviewerLoad, 0, len(users))
+	for _, u := range users {
+		reviewers = append(reviewers, ReviewerLoad{UserID: u.ID, Name: u.Name, Email: u.Email, Pending: load[u.ID]})
+	}
+
+	strategy := lt.AssignmentStrategy
+	if strategy == "" {
+		strategy = assignment.StrategyRoundRobin
+	}
+	c.JSON(http.StatusOK, LetterTypeReviewersResponse{
+		TypeID:             lt.ID,
+		AssignmentStrategy: strategy,
+		UsesPool:           pooled > 0,
+		Reviewers:          reviewers,
+	})
+}
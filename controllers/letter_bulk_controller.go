@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxBulkLetters adalah batas jumlah surat dalam satu keputusan massal
+const maxBulkLetters = 500
+
+// LetterBulkDecisionInput digunakan reviewer / admin untuk memutuskan banyak surat sekaligus
+type LetterBulkDecisionInput struct {
+	LetterIDs    []uint `json:"letter_ids" binding:"required" example:"1,2,3"`
+	Status       string `json:"status" binding:"required" example:"accepted"` // accepted / rejected
+	RejectReason string `json:"reject_reason" example:"Berkas tidak lengkap"`
+}
+
+// BulkDecisionResult adalah hasil keputusan untuk satu surat
+type BulkDecisionResult struct {
+	LetterID uint   `json:"letter_id"`
+	Success  bool   `json:"success"`
+	Status   string `json:"status,omitempty"` // status surat setelah diproses
+	Code     int    `json:"code"`             // kode HTTP yang setara untuk surat ini
+	Error    string `json:"error,omitempty"`
+}
+
+// LetterBulkDecisionResponse adalah ringkasan & hasil per surat dari keputusan massal
+type LetterBulkDecisionResponse struct {
+	Processed int                  `json:"processed"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Results   []BulkDecisionResult `json:"results"`
+}
+
+// bulkItemError memetakan error satu surat ke kode HTTP & pesan, sama seperti respondTxError
+func bulkItemError(err error) (int, string) {
+	var denied *policy.DeniedError
+	var tErr *workflow.TransitionError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound, "Letter not found"
+	case errors.As(err, &denied):
+		return http.StatusForbidden, err.Error()
+	case errors.As(err, &tErr):
+		if !workflow.IsValid(tErr.To) {
+			return http.StatusBadRequest, err.Error()
+		}
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, errLetterChanged):
+		return http.StatusConflict, err.Error()
+	}
+	return http.StatusInternalServerError, "Gagal memproses surat"
+}
+
+// BulkDecideLetters godoc
+// @Summary Bulk accept / reject letters
+// @Description Terima atau tolak banyak surat sekaligus (reviewer & admin). Setiap surat diproses di savepoint sendiri
+// @Description dalam satu transaksi, sehingga surat yang gagal tidak membatalkan surat lain. Setiap pemohon & approver
+// @Description berikutnya hanya menerima satu notifikasi ringkasan.
+// @Tags Letters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body LetterBulkDecisionInput true "Daftar surat & keputusan"
+// @Success 200 {object} LetterBulkDecisionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /letters/bulk-decision [post]
+func BulkDecideLetters(c *gin.Context) {
+	by := currentActor(c)
+	if !authorizeLetter(c, policy.ActionReview, nil) {
+		return
+	}
+
+	var input LetterBulkDecisionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Status != workflow.StatusAccepted && input.Status != workflow.StatusRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Keputusan massal hanya untuk status accepted atau rejected"})
+		return
+	}
+	if err := policy.AuthorizeStatus(by, input.Status); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	// buang ID ganda dengan tetap menjaga urutan
+	seen := map[uint]bool{}
+	ids := make([]uint, 0, len(input.LetterIDs))
+	for _, id := range input.LetterIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(ids) > maxBulkLetters {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "letter_ids harus berisi 1 - 500 surat"})
+		return
+	}
+
+	results := make([]BulkDecisionResult, 0, len(ids))
+	outcomes := map[uint]*reviewOutcome{}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			var letter models.Letter
+			var outcome *reviewOutcome
+
+			// transaksi bersarang = SAVEPOINT, kegagalan satu surat hanya membatalkan surat itu
+			err := tx.Transaction(func(item *gorm.DB) error {
+				if err := item.Clauses(clause.Locking{Strength: "UPDATE"}).First(&letter, id).Error; err != nil {
+					return err
+				}
+				var err error
+				outcome, err = reviewLetter(item, by, &letter, input.Status, input.RejectReason)
+				return err
+			})
+
+			if err != nil {
+				code, msg := bulkItemError(err)
+				results = append(results, BulkDecisionResult{LetterID: id, Code: code, Error: msg})
+				continue
+			}
+			outcomes[id] = outcome
+			results = append(results, BulkDecisionResult{LetterID: id, Success: true, Status: letter.Status, Code: http.StatusOK})
+		}
+		if len(outcomes) == 0 {
+			return nil
+		}
+
+		decided := make([]uint, 0, len(outcomes))
+		for id := range outcomes {
+			decided = append(decided, id)
+		}
+		var letters []models.Letter
+		if err := tx.Preload("User").Preload("LetterType").Order("id").Find(&letters, decided).Error; err != nil {
+			return err
+		}
+		return notifyBulkDecisions(tx, letters, outcomes)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memproses keputusan massal"})
+		return
+	}
+
+	response := LetterBulkDecisionResponse{Processed: len(results), Results: results}
+	for _, r := range results {
+		if r.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
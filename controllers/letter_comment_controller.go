@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LetterCommentInput digunakan untuk menulis komentar pada surat
+type LetterCommentInput struct {
+	Body     string `json:"body" binding:"required" example:"Mohon lampirkan KTM terbaru"`
+	Internal bool   `json:"internal" example:"false"` // hanya reviewer & admin
+}
+
+// findCommentLetter mengambil surat & memastikan actor boleh ikut berdiskusi
+func findCommentLetter(c *gin.Context) (models.Letter, bool) {
+	var letter models.Letter
+	if err := config.DB.Preload("User").Preload("LetterType").
+		First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return letter, false
+	}
+	return letter, authorizeLetter(c, policy.ActionComment, &letter)
+}
+
+// ===============================
+// Get Comments
+// ===============================
+
+// GetLetterComments godoc
+// @Summary Get letter comments
+// @Description Ambil komentar surat urut dari yang terlama. Komentar internal hanya terlihat oleh reviewer & admin.
+// @Tags Letters
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Success 200 {array} models.LetterComment
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/comments [get]
+func GetLetterComments(c *gin.Context) {
+	letter, ok := findCommentLetter(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Preload("User").Where("letter_id = ?", letter.ID)
+	if policy.Authorize(currentActor(c), policy.ActionCommentInternal, &letter) != nil {
+		query = query.Where("internal = ?", false)
+	}
+
+	var comments []models.LetterComment
+	query.Order("created_at, id").Find(&comments)
+	c.JSON(http.StatusOK, comments)
+}
+
+// ===============================
+// Create Comment
+// ===============================
+
+// CreateLetterComment godoc
+// @Summary Comment on a letter
+// @Description Tulis komentar / pertanyaan pada surat. Pemohon diberi tahu saat reviewer berkomentar, dan sebaliknya.
+// @Tags Letters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Param request body LetterCommentInput true "Isi komentar"
+// @Success 201 {object} models.LetterComment
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/comments [post]
+func CreateLetterComment(c *gin.Context) {
+	by := currentActor(c)
+
+	letter, ok := findCommentLetter(c)
+	if !ok {
+		return
+	}
+
+	var input LetterCommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Body == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Komentar tidak boleh kosong"})
+		return
+	}
+	if input.Internal && !authorizeLetter(c, policy.ActionCommentInternal, &letter) {
+		return
+	}
+
+	comment := models.LetterComment{
+		LetterID: letter.ID,
+		UserID:   by.ID,
+		Body:     input.Body,
+		Internal: input.Internal,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("User").First(&comment, comment.ID).Error; err != nil {
+			return err
+		}
+		return notifyNewComment(tx, letter, comment, by)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan komentar"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// ===============================
+// Delete Comment
+// ===============================
+
+// DeleteLetterComment godoc
+// @Summary Delete a letter comment
+// @Description Hapus komentar (penulis komentar atau admin)
+// @Tags Letters
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Param comment_id path int true "Comment ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/comments/{comment_id} [delete]
+func DeleteLetterComment(c *gin.Context) {
+	by := currentActor(c)
+
+	letter, ok := findCommentLetter(c)
+	if !ok {
+		return
+	}
+
+	// Pemohon tidak boleh tahu keberadaan komentar internal
+	query := config.DB.Where("letter_id = ?", letter.ID)
+	if policy.Authorize(by, policy.ActionCommentInternal, &letter) != nil {
+		query = query.Where("internal = ?", false)
+	}
+
+	var comment models.LetterComment
+	if err := query.First(&comment, c.Param("comment_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if err := policy.AuthorizeComment(by, comment); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	config.DB.Delete(&comment)
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
@@ -1,14 +1,26 @@
 package controllers
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/document"
+	"sanbercode-golang-batch-70-final-project/forms"
+	"sanbercode-golang-batch-70-final-project/listing"
 	"sanbercode-golang-batch-70-final-project/models"
-	"sanbercode-golang-batch-70-final-project/notification"
+	"sanbercode-golang-batch-70-final-project/numbering"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/storage"
+	"sanbercode-golang-batch-70-final-project/workflow"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ===============================
@@ -16,17 +28,171 @@ import (
 // ===============================
 
 // LetterCreateInput digunakan untuk membuat surat baru
+// (bisa JSON atau multipart/form-data kalau sekaligus upload lampiran)
 type LetterCreateInput struct {
-	UserID uint `json:"user_id,omitempty" example:"5"`
-	TypeID uint `json:"type_id" example:"1" binding:"required"`
+	UserID  uint           `json:"user_id,omitempty" form:"user_id" example:"5"`
+	TypeID  uint           `json:"type_id" form:"type_id" example:"1" binding:"required"`
+	Subject string         `json:"subject" form:"subject" example:"Surat keterangan aktif kuliah" binding:"required,max=200"`
+	Purpose string         `json:"purpose" form:"purpose" example:"Syarat pengajuan beasiswa" binding:"required"`
+	Body    string         `json:"body,omitempty" form:"body" example:"Mohon dibuatkan surat keterangan aktif kuliah semester ganjil."`
+	Fields  models.JSONMap `json:"fields,omitempty" form:"-" swaggertype:"object"`
+	Draft   bool           `json:"draft,omitempty" form:"draft" example:"false"`
+}
+
+// LetterCancelInput digunakan pemilik surat untuk menarik pengajuan
+type LetterCancelInput struct {
+	Reason string `json:"reason" example:"Salah memilih jenis surat" binding:"required"`
 }
 
 // LetterUpdateInput digunakan untuk update surat
 type LetterUpdateInput struct {
-	UserID       uint   `json:"user_id,omitempty" example:"4"`
-	TypeID       uint   `json:"type_id,omitempty" example:"3"`
-	Status       string `json:"status,omitempty" example:"accepted"`
-	RejectReason string `json:"reject_reason,omitempty" example:"Ditolak untuk testing"`
+	UserID       uint           `json:"user_id,omitempty" example:"4"`
+	TypeID       uint           `json:"type_id,omitempty" example:"3"`
+	Subject      *string        `json:"subject,omitempty" example:"Surat keterangan aktif kuliah" binding:"omitempty,max=200"`
+	Purpose      *string        `json:"purpose,omitempty" example:"Syarat pengajuan beasiswa"`
+	Body         *string        `json:"body,omitempty" example:"Isi surat yang sudah diperbaiki"`
+	Fields       models.JSONMap `json:"fields,omitempty" swaggertype:"object"`
+	Status       string         `json:"status,omitempty" example:"accepted"`
+	RejectReason string         `json:"reject_reason,omitempty" example:"Ditolak untuk testing"`
+}
+
+// hasContent mengecek apakah input update mengubah isi surat
+func (in LetterUpdateInput) hasContent() bool {
+	return in.Subject != nil || in.Purpose != nil || in.Body != nil || in.Fields != nil
+}
+
+// ===============================
+// Helper status
+// ===============================
+
+// statusChange adalah satu langkah perpindahan status yang dicatat di riwayat
+type statusChange struct {
+	From string
+	To   string
+}
+
+// changeLetterStatus memindahkan status surat lewat state machine workflow.
+// Surat yang masih "submitted" otomatis melewati "in_review" saat diputuskan.
+func changeLetterStatus(letter *models.Letter, status, reason string) ([]statusChange, error) {
+	path, err := workflow.Path(letter.Status, status)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []statusChange
+	for _, next := range path {
+		changes = append(changes, statusChange{From: letter.Status, To: next})
+		letter.Status = next
+	}
+
+	switch letter.Status {
+	case workflow.StatusAccepted:
+		letter.RejectReason = ""
+	case workflow.StatusRejected:
+		letter.RejectReason = reason
+	}
+	return changes, nil
+}
+
+// errLetterChanged dikembalikan kalau status surat berubah di tengah proses
+// (misal sudah diputuskan reviewer lain pada saat yang sama)
+var errLetterChanged = errors.New("Status surat sudah diubah oleh user lain, silakan muat ulang")
+
+// lockLetter mengunci baris surat di dalam transaksi dan memastikan statusnya
+// masih sama dengan status saat surat dibaca
+func lockLetter(tx *gorm.DB, id uint, status string) error {
+	var current models.Letter
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "status").First(&current, id).Error; err != nil {
+		return err
+	}
+	if current.Status != status {
+		return errLetterChanged
+	}
+	return nil
+}
+
+// issueLetter memberi nomor surat resmi, tanggal terbit & token verifikasi saat surat disetujui.
+// Harus dipanggil di dalam transaksi yang sama dengan perubahan status.
+func issueLetter(tx *gorm.DB, letter *models.Letter) error {
+	if letter.Status != workflow.StatusAccepted || letter.Number != nil {
+		return nil
+	}
+	return completeIssue(tx, letter, time.Now())
+}
+
+// completeIssue mengisi nomor surat, tanggal terbit (issuedAt) & token verifikasi yang belum ada
+func completeIssue(tx *gorm.DB, letter *models.Letter, issuedAt time.Time) error {
+	if letter.Number == nil {
+		var letterType models.LetterType
+		if err := tx.First(&letterType, letter.TypeID).Error; err != nil {
+			return err
+		}
+		number, err := numbering.Next(tx, letterType, issuedAt)
+		if err != nil {
+			return err
+		}
+		letter.Number = &number
+		letter.IssuedAt = &issuedAt
+	}
+	if letter.VerificationToken == nil {
+		token, err := document.NewVerificationToken()
+		if err != nil {
+			return err
+		}
+		letter.VerificationToken = &token
+	}
+	return nil
+}
+
+// respondTxError memetakan error dari dalam transaksi surat ke response:
+// 403 akses ditolak, 409 transisi ilegal / surat keburu berubah, selain itu 500
+func respondTxError(c *gin.Context, err error, message string) {
+	var denied *policy.DeniedError
+	var tErr *workflow.TransitionError
+	switch {
+	case errors.As(err, &denied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.As(err, &tErr):
+		respondStatusError(c, err)
+	case errors.Is(err, errLetterChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// validateLetterContent memastikan subject & purpose surat tidak kosong
+func validateLetterContent(letter *models.Letter) error {
+	letter.Subject = strings.TrimSpace(letter.Subject)
+	letter.Purpose = strings.TrimSpace(letter.Purpose)
+	if letter.Subject == "" {
+		return errors.New("Subject surat wajib diisi")
+	}
+	if letter.Purpose == "" {
+		return errors.New("Tujuan (purpose) surat wajib diisi")
+	}
+	return nil
+}
+
+// validateLetterFields mengecek isian surat terhadap form schema jenis suratnya.
+// Mengirim response 400 dan mengembalikan false kalau tidak valid.
+func validateLetterFields(c *gin.Context, letterType models.LetterType, fields models.JSONMap) bool {
+	if errs := forms.ValidateValues(letterType.FormSchema, fields); errs != nil {
+		respondFieldErrors(c, "Isian form surat tidak valid", errs)
+		return false
+	}
+	return true
+}
+
+// respondStatusError mengirim 409 untuk transisi ilegal, 400 untuk error lain
+func respondStatusError(c *gin.Context, err error) {
+	var tErr *workflow.TransitionError
+	if errors.As(err, &tErr) && workflow.IsValid(tErr.To) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "from": tErr.From, "to": tErr.To})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 }
 
 // ===============================
@@ -35,42 +201,57 @@ type LetterUpdateInput struct {
 
 // CreateLetter godoc
 // @Summary Create a new letter
-// @Description Buat pengajuan surat baru (user & admin bisa)
+// @Description Buat pengajuan surat baru (user & admin bisa).
+// @Description Kirim sebagai multipart/form-data untuk sekaligus upload lampiran di field "attachments"
+// @Description (field "fields" berisi string JSON).
 // @Tags Letters
-// @Accept json
+// @Accept json,mpfd
 // @Produce json
 // @Security BearerAuth
 // @Param request body LetterCreateInput true "Letter create payload"
 // @Success 201 {object} models.Letter
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} map[string]interface{}
 // @Failure 403 {object} map[string]string
 // @Router /letters/ [post]
 func CreateLetter(c *gin.Context) {
-	roleVal, _ := c.Get("role")
-	role := fmt.Sprintf("%v", roleVal)
+	by := currentActor(c)
 
 	// Reviewer tidak boleh bikin surat
-	if role == "reviewer" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Reviewer hanya bisa menerima atau menolak pengajuan surat!"})
+	if !authorizeLetter(c, policy.ActionCreate, nil) {
 		return
 	}
 
 	var input LetterCreateInput
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	isMultipart := c.ContentType() == "multipart/form-data"
+	var bindErr error
+	if isMultipart {
+		bindErr = c.ShouldBind(&input)
+	} else {
+		bindErr = c.ShouldBindJSON(&input)
+	}
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": bindErr.Error()})
 		return
 	}
 
-	var userID uint
-	if role == "user" {
-		// Ambil user_id dari token (bukan dari body)
-		uid, exists := c.Get("user_id")
-		if !exists {
-			c.JSON(http.StatusForbidden, gin.H{"error": "User ID tidak ditemukan di token"})
+	// Request multipart: isian custom dikirim sebagai string JSON + file lampiran
+	var uploads []attachmentUpload
+	if isMultipart {
+		if raw := c.PostForm("fields"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &input.Fields); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Field 'fields' harus berupa objek JSON"})
+				return
+			}
+		}
+		var err error
+		if uploads, err = checkAttachments(multipartFiles(c)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		userID = uid.(uint)
-	} else if role == "admin" {
+	}
+
+	var userID uint
+	if by.Role == policy.RoleAdmin {
 		// Admin wajib isi user_id
 		if input.UserID == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Admin harus menentukan user_id untuk surat ini"})
@@ -78,8 +259,8 @@ func CreateLetter(c *gin.Context) {
 		}
 		userID = input.UserID
 	} else {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Role tidak dikenali"})
-		return
+		// Ambil user_id dari token (bukan dari body)
+		userID = by.ID
 	}
 
 	// Validasi user
@@ -96,40 +277,236 @@ func CreateLetter(c *gin.Context) {
 		return
 	}
 
-	// Buat surat baru
+	// Draft boleh belum lengkap, form schema dicek saat surat dikirim
+	if !input.Draft && !validateLetterFields(c, letterType, input.Fields) {
+		return
+	}
+
+	// Buat surat baru (selalu mulai dari draft)
 	letter := models.Letter{
 		UserID:       userID,
 		TypeID:       input.TypeID,
-		Status:       "pending",
+		Subject:      input.Subject,
+		Purpose:      input.Purpose,
+		Body:         input.Body,
+		Fields:       input.Fields,
+		Status:       workflow.StatusDraft,
 		RejectReason: "",
 	}
+	if err := validateLetterContent(&letter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	changes := []statusChange{{From: "", To: workflow.StatusDraft}}
+	if !input.Draft {
+		submitted, err := changeLetterStatus(&letter, workflow.StatusSubmitted, "")
+		if err != nil {
+			respondStatusError(c, err)
+			return
+		}
+		changes = append(changes, submitted...)
+	}
 
-	if err := config.DB.Create(&letter).Error; err != nil {
+	var saved []models.Attachment
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&letter).Error; err != nil {
+			return err
+		}
+		if err := recordLetterHistory(tx, letter.ID, by, changes, ""); err != nil {
+			return err
+		}
+		if letter.Status == workflow.StatusSubmitted {
+			if err := assignReviewer(tx, &letter); err != nil {
+				return err
+			}
+			if err := startReviewClock(tx, &letter); err != nil {
+				return err
+			}
+		}
+		var err error
+		if saved, err = storeAttachments(c.Request.Context(), tx, letter.ID, by.ID, uploads); err != nil {
+			return err
+		}
+
+		// Draft belum dikirim ke reviewer
+		if letter.Status == workflow.StatusDraft {
+			return nil
+		}
+		notice, err := noticeLetter(tx, letter.ID)
+		if err != nil {
+			return err
+		}
+		return notifyNewLetter(tx, notice)
+	})
+	if err != nil {
+		// file lampiran sudah terlanjur diunggah sebelum transaksi di-rollback
+		deleteAttachmentFiles(saved)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat surat"})
 		return
 	}
 
+	config.DB.Preload("User.Role").Preload("LetterType").Preload("Attachments").First(&letter, letter.ID)
+	c.JSON(http.StatusCreated, letter)
+}
+
+// ===============================
+// Submit Letter
+// ===============================
+
+// SubmitLetter godoc
+// @Summary Submit a draft letter
+// @Description Kirim surat berstatus draft ke reviewer (pemilik surat & admin)
+// @Tags Letters
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Success 200 {object} models.Letter
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /letters/{id}/submit [post]
+func SubmitLetter(c *gin.Context) {
+	var letter models.Letter
+	if err := config.DB.Preload("User").Preload("LetterType").
+		First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+
+	if !authorizeLetter(c, policy.ActionSubmit, &letter) {
+		return
+	}
+
+	if !validateLetterFields(c, letter.LetterType, letter.Fields) {
+		return
+	}
+
+	changes, err := changeLetterStatus(&letter, workflow.StatusSubmitted, "")
+	if err != nil {
+		respondStatusError(c, err)
+		return
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockLetter(tx, letter.ID, changes[0].From); err != nil {
+			return err
+		}
+		if err := tx.Model(&letter).Update("status", letter.Status).Error; err != nil {
+			return err
+		}
+		if err := assignReviewer(tx, &letter); err != nil {
+			return err
+		}
+		if err := startReviewClock(tx, &letter); err != nil {
+			return err
+		}
+		if err := recordLetterHistory(tx, letter.ID, currentActor(c), changes, ""); err != nil {
+			return err
+		}
+		notice, err := noticeLetter(tx, letter.ID)
+		if err != nil {
+			return err
+		}
+		return notifyNewLetter(tx, notice)
+	})
+	if err != nil {
+		respondTxError(c, err, "Gagal mengirim surat")
+		return
+	}
+
 	config.DB.Preload("User.Role").Preload("LetterType").First(&letter, letter.ID)
+	c.JSON(http.StatusOK, letter)
+}
 
-	// Kirim notifikasi ke semua reviewer yang aktif
-	var settings []models.Setting
-	config.DB.Preload("User.Role").Where("allow_telegram = 'yes' OR allow_wa = 'yes'").Find(&settings)
+// ===============================
+// Cancel Letter
+// ===============================
 
-	message := fmt.Sprintf("📩 Pengajuan surat baru dari *%s* untuk jenis surat *%s* (status: pending).",
-		user.Name, letterType.Name)
+// CancelLetter godoc
+// @Summary Cancel (withdraw) a letter
+// @Description Pemilik surat menarik pengajuan yang belum diputuskan beserta alasannya. Reviewer akan diberi tahu.
+// @Description Admin juga bisa mencabut surat yang sudah diterima; pemilik surat akan diberi tahu dan verifikasi surat menjadi tidak berlaku.
+// @Tags Letters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Param request body LetterCancelInput true "Alasan pembatalan"
+// @Success 200 {object} models.Letter
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /letters/{id}/cancel [post]
+func CancelLetter(c *gin.Context) {
+	by := currentActor(c)
 
-	for _, s := range settings {
-		if s.User.Role.Name == "reviewer" {
-			if s.AllowTelegram == "yes" && s.TelegramChatID != "" {
-				go notification.SendTelegram(s.TelegramChatID, message)
-			}
-			if s.AllowWA == "yes" && s.WANumber != "" {
-				go notification.SendWhatsApp(s.WANumber, message)
-			}
+	var letter models.Letter
+	if err := config.DB.Preload("User").Preload("LetterType").
+		First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+	if !authorizeLetter(c, policy.ActionCancel, &letter) {
+		return
+	}
+
+	var input LetterCancelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alasan pembatalan wajib diisi"})
+		return
+	}
+
+	// Reviewer hanya pernah diberi tahu kalau surat sudah dikirim (bukan draft)
+	wasSubmitted := workflow.IsPending(letter.Status)
+	// Surat yang sudah terbit dicabut admin; pemilik surat diberi tahu
+	revoked := letter.Status == workflow.StatusAccepted
+
+	oldStatus := letter.Status
+	changes, err := changeLetterStatus(&letter, workflow.StatusCancelled, "")
+	if err != nil {
+		respondStatusError(c, err)
+		return
+	}
+	letter.CancelReason = input.Reason
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockLetter(tx, letter.ID, oldStatus); err != nil {
+			return err
+		}
+		if err := tx.Model(&letter).Updates(map[string]interface{}{
+			"status":        letter.Status,
+			"cancel_reason": letter.CancelReason,
+		}).Error; err != nil {
+			return err
+		}
+		if err := recordLetterHistory(tx, letter.ID, by, changes, input.Reason); err != nil {
+			return err
+		}
+		if !wasSubmitted && !revoked {
+			return nil
+		}
+		notice, err := noticeLetter(tx, letter.ID)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return notifyStatusChange(tx, notice)
 		}
+		return notifyLetterCancelled(tx, notice)
+	})
+	if err != nil {
+		respondTxError(c, err, "Gagal membatalkan surat")
+		return
 	}
 
-	c.JSON(http.StatusCreated, letter)
+	config.DB.Preload("User.Role").Preload("LetterType").First(&letter, letter.ID)
+	c.JSON(http.StatusOK, letter)
 }
 
 // ===============================
@@ -138,27 +515,46 @@ func CreateLetter(c *gin.Context) {
 
 // GetLetters godoc
 // @Summary Get all letters
-// @Description Get all letters (admin & reviewer only)
+// @Description Ambil daftar surat per halaman. User hanya melihat surat miliknya, admin & reviewer melihat semua.
 // @Tags Letters
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Letter
+// @Param page query int false "Halaman (mulai dari 1)"
+// @Param limit query int false "Jumlah per halaman (maks 100)"
+// @Param sort query string false "Urutan, misal -created_at atau status,-id" default(-created_at)
+// @Param status query string false "Filter status, pisahkan dengan koma"
+// @Param type_id query int false "Filter jenis surat"
+// @Param user_id query int false "Filter pemohon"
+// @Param created_from query string false "Tanggal dibuat dari (YYYY-MM-DD)"
+// @Param created_to query string false "Tanggal dibuat sampai (YYYY-MM-DD)"
+// @Param overdue query bool false "Hanya surat yang melewati tenggat review"
+// @Param q query string false "Cari di nama pemohon, perihal, isi & nomor surat"
+// @Success 200 {object} LetterListResponse
+// @Failure 400 {object} map[string]string
 // @Router /letters/ [get]
 func GetLetters(c *gin.Context) {
-	role, _ := c.Get("role")
+	params, err := letterListSpec.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, err := parseLetterFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// User hanya melihat surat miliknya, admin & reviewer melihat semua
+	query := letterListQuery(config.DB, currentActor(c), filter)
 
-	if role == "user" {
-		uid, _ := c.Get("user_id")
-		var letters []models.Letter
-		config.DB.Preload("User.Role").Preload("LetterType").
-			Where("user_id = ?", uid).Find(&letters)
-		c.JSON(http.StatusOK, letters)
+	letters := []models.Letter{}
+	paging, err := listing.Find(query, params, &letters, preloadLetterList)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil surat"})
 		return
 	}
 
-	var letters []models.Letter
-	config.DB.Preload("User.Role").Preload("LetterType").Find(&letters)
-	c.JSON(http.StatusOK, letters)
+	c.JSON(http.StatusOK, LetterListResponse{Items: letters, Paging: paging})
 }
 
 // ===============================
@@ -168,10 +564,18 @@ func GetLetters(c *gin.Context) {
 func GetLetterByID(c *gin.Context) {
 	var letter models.Letter
 	if err := config.DB.Preload("User.Role").Preload("LetterType").
+		Preload("AssignedReviewer").Preload("Attachments").Preload("Approvals", orderApprovals).
 		First(&letter, c.Param("id")).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
 		return
 	}
+	if !authorizeLetter(c, policy.ActionView, &letter) {
+		return
+	}
+	if err := loadLetterNotifications(currentActor(c), &letter); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil notifikasi surat"})
+		return
+	}
 	c.JSON(http.StatusOK, letter)
 }
 
@@ -180,7 +584,7 @@ func GetLetterByID(c *gin.Context) {
 // ===============================
 
 func UpdateLetter(c *gin.Context) {
-	role, _ := c.Get("role")
+	by := currentActor(c)
 
 	var letter models.Letter
 	if err := config.DB.Preload("User").Preload("LetterType").
@@ -188,6 +592,7 @@ func UpdateLetter(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
 		return
 	}
+	oldStatus := letter.Status
 
 	var input LetterUpdateInput
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -195,74 +600,96 @@ func UpdateLetter(c *gin.Context) {
 		return
 	}
 
-	switch role {
-	case "admin":
+	editsData := input.UserID != 0 || input.TypeID != 0 || input.hasContent()
+	changesStatus := input.Status != "" && input.Status != letter.Status
+	if !editsData && !changesStatus {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak ada data surat yang diubah"})
+		return
+	}
+
+	// Ubah pemilik (admin), jenis & isi surat (admin, atau pemohon selama masih draft)
+	if editsData {
+		if !authorizeLetter(c, policy.ActionEdit, &letter) {
+			return
+		}
+		if input.UserID != 0 && input.UserID != letter.UserID && !authorizeLetter(c, policy.ActionTransfer, &letter) {
+			return
+		}
 		if input.UserID != 0 {
 			letter.UserID = input.UserID
 		}
 		if input.TypeID != 0 {
 			letter.TypeID = input.TypeID
 		}
-		if input.Status != "" {
-			letter.Status = input.Status
-			if input.Status == "accepted" {
-				letter.RejectReason = ""
-			} else if input.Status == "rejected" {
-				letter.RejectReason = input.RejectReason
-			}
+		if input.Subject != nil {
+			letter.Subject = *input.Subject
 		}
-	case "reviewer":
-		if input.Status == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Reviewer hanya bisa mengubah status surat"})
-			return
+		if input.Purpose != nil {
+			letter.Purpose = *input.Purpose
 		}
-		if input.UserID != 0 || input.TypeID != 0 {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Reviewer tidak boleh ubah ID pengguna/jenis surat"})
-			return
+		if input.Body != nil {
+			letter.Body = *input.Body
 		}
-		if input.Status == "accepted" {
-			letter.Status = "accepted"
-			letter.RejectReason = ""
-		} else if input.Status == "rejected" {
-			letter.Status = "rejected"
-			letter.RejectReason = input.RejectReason
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Status tidak valid untuk reviewer"})
-			return
+		if input.Fields != nil {
+			letter.Fields = input.Fields
+		}
+		if input.hasContent() {
+			if err := validateLetterContent(&letter); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 		}
-	case "user":
-		c.JSON(http.StatusForbidden, gin.H{"error": "User hanya bisa mengajukan surat!"})
-		return
-	default:
-		c.JSON(http.StatusForbidden, gin.H{"error": "Role tidak dikenali"})
-		return
-	}
 
-	if err := config.DB.Save(&letter).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update surat"})
-		return
+		// Isian dicek ulang kalau jenis surat atau isiannya berubah
+		if (input.TypeID != 0 || input.Fields != nil) && letter.Status != workflow.StatusDraft {
+			var letterType models.LetterType
+			if err := config.DB.First(&letterType, letter.TypeID).Error; err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Letter type tidak ditemukan"})
+				return
+			}
+			if !validateLetterFields(c, letterType, letter.Fields) {
+				return
+			}
+		}
 	}
 
-	config.DB.Preload("User.Role").Preload("LetterType").First(&letter, letter.ID)
-
-	// Kirim notifikasi ke user
-	var setting models.Setting
-	if err := config.DB.Where("user_id = ?", letter.UserID).First(&setting).Error; err == nil {
-		message := fmt.Sprintf("📢 Status surat kamu (%s) kini: *%s*.",
-			letter.LetterType.Name, letter.Status)
-
-		if letter.Status == "rejected" && letter.RejectReason != "" {
-			message += fmt.Sprintf("\nAlasan: %s", letter.RejectReason)
+	// Ubah status surat (admin & reviewer) lewat reviewLetter supaya rantai
+	// persetujuan, nomor surat, riwayat & notifikasi diproses di transaksi yang sama
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockLetter(tx, letter.ID, oldStatus); err != nil {
+			return err
+		}
+		if editsData {
+			if err := tx.Omit(clause.Associations).Save(&letter).Error; err != nil {
+				return err
+			}
+		}
+		if !changesStatus {
+			// Perubahan data tanpa perpindahan status tetap dicatat di riwayat
+			changes := []statusChange{{From: letter.Status, To: letter.Status}}
+			return recordLetterHistory(tx, letter.ID, by, changes, "Data surat diubah")
 		}
 
-		if setting.AllowTelegram == "yes" && setting.TelegramChatID != "" {
-			go notification.SendTelegram(setting.TelegramChatID, message)
+		outcome, err := reviewLetter(tx, by, &letter, input.Status, input.RejectReason)
+		if err != nil {
+			return err
 		}
-		if setting.AllowWA == "yes" && setting.WANumber != "" {
-			go notification.SendWhatsApp(setting.WANumber, message)
+
+		// Kirim notifikasi ke user & approver tahap berikutnya
+		notice, err := noticeLetter(tx, letter.ID)
+		if err != nil {
+			return err
 		}
+		return notifyReviewOutcome(tx, notice, outcome)
+	})
+	if err != nil {
+		respondTxError(c, err, "Gagal update surat")
+		return
 	}
 
+	config.DB.Preload("User.Role").Preload("LetterType").Preload("AssignedReviewer").
+		Preload("Approvals", orderApprovals).First(&letter, letter.ID)
+
 	c.JSON(http.StatusOK, letter)
 }
 
@@ -271,20 +698,46 @@ func UpdateLetter(c *gin.Context) {
 // ===============================
 
 func DeleteLetter(c *gin.Context) {
-	role, _ := c.Get("role")
-
-	if role == "reviewer" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Reviewer hanya bisa menerima atau menolak pengajuan surat!"})
+	var letter models.Letter
+	if err := config.DB.First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
 		return
 	}
-	if role == "user" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "User hanya bisa mengajukan surat!"})
+	if !authorizeLetter(c, policy.ActionDelete, &letter) {
 		return
 	}
 
-	if err := config.DB.Delete(&models.Letter{}, c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+	var attachmentKeys []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if attachmentKeys, err = deleteLetterAttachments(tx, letter.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("letter_id = ?", letter.ID).Delete(&models.LetterApproval{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("letter_id = ?", letter.ID).Delete(&models.LetterComment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("letter_id = ?", letter.ID).Delete(&models.LetterRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&letter).Error; err != nil {
+			return err
+		}
+		changes := []statusChange{{From: letter.Status, To: workflow.StatusDeleted}}
+		return recordLetterHistory(tx, letter.ID, currentActor(c), changes, "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus surat"})
 		return
 	}
+
+	// File di storage baru dihapus setelah data di database pasti terhapus
+	for _, key := range attachmentKeys {
+		if err := storage.Files.Delete(c.Request.Context(), key); err != nil {
+			log.Println("Gagal hapus file lampiran:", key, err)
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Letter deleted"})
 }
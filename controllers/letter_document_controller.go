@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/document"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetLetterPDF godoc
+// @Summary Download letter PDF
+// @Description Generate dokumen PDF dari template jenis surat untuk surat yang sudah disetujui (accepted) dan tidak pernah dicabut
+// @Tags Letters
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Success 200 {file} file
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /letters/{id}/pdf [get]
+func GetLetterPDF(c *gin.Context) {
+	var letter models.Letter
+	if err := config.DB.Preload("User").Preload("LetterType").
+		First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+
+	if !authorizeLetter(c, policy.ActionView, &letter) {
+		return
+	}
+
+	// Surat harus sudah disetujui & tidak pernah dicabut (surat arsip tetap bisa diunduh
+	// kalau pernah accepted), aturan yang sama dengan verifikasi QR code
+	approval, ok := issuedApproval(letter)
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "PDF hanya tersedia untuk surat yang sudah disetujui dan tidak dicabut"})
+		return
+	}
+
+	// Surat yang disetujui sebelum ada penomoran / verifikasi dilengkapi sekarang,
+	// dengan tanggal persetujuannya sebagai tanggal terbit
+	if letter.Number == nil || letter.VerificationToken == nil {
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			var current models.Letter
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, letter.ID).Error; err != nil {
+				return err
+			}
+			if err := completeIssue(tx, &current, approval.CreatedAt); err != nil {
+				return err
+			}
+			letter.Number = current.Number
+			letter.IssuedAt = current.IssuedAt
+			letter.VerificationToken = current.VerificationToken
+			return tx.Model(&current).Select("number", "issued_at", "verification_token").Updates(&current).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal melengkapi nomor surat"})
+			return
+		}
+	}
+
+	data := letterDocumentData(letter, approval)
+	data.VerifyURL = verificationURL(*letter.VerificationToken)
+
+	var buf bytes.Buffer
+	if err := document.WritePDF(&buf, letter.LetterType.Template, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF: " + err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("surat-%d.pdf", letter.ID)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// letterDocumentData menyiapkan placeholder template dari surat & riwayat persetujuannya
+func letterDocumentData(letter models.Letter, approval models.LetterStatusHistory) document.Data {
+	var signer models.User
+	config.DB.Preload("Role").First(&signer, approval.ActorID)
+
+	data := document.Data{
+		TypeName:   letter.LetterType.Name,
+		UserName:   letter.User.Name,
+		UserEmail:  letter.User.Email,
+		Subject:    letter.Subject,
+		Purpose:    letter.Purpose,
+		Body:       letter.Body,
+		Fields:     letter.Fields,
+		IssuedAt:   approval.CreatedAt,
+		SignerName: signer.Name,
+		SignerRole: signer.Role.Name,
+	}
+	if letter.Number != nil {
+		data.Number = *letter.Number
+	}
+	if letter.IssuedAt != nil {
+		data.IssuedAt = *letter.IssuedAt
+	}
+	return data
+}
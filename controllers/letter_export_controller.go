@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/export"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize adalah jumlah surat yang diambil per query saat export di-stream
+const exportBatchSize = 500
+
+// exportTimeLayout adalah format tanggal & jam di file export
+const exportTimeLayout = "2006-01-02 15:04"
+
+// letterExportHeader adalah judul kolom file export surat
+var letterExportHeader = []string{
+	"ID", "Nomor Surat", "Tanggal Pengajuan", "Pemohon", "Email Pemohon", "Jenis Surat",
+	"Perihal", "Status", "Reviewer", "Tanggal Keputusan", "Alasan Penolakan",
+}
+
+// letterDecision adalah keputusan akhir (diterima / ditolak) surat dari riwayat status
+type letterDecision struct {
+	LetterID  uint
+	DecidedAt time.Time
+	Reviewer  string
+}
+
+// loadLetterDecisions mengambil keputusan terakhir setiap surat beserta nama pemutusnya
+func loadLetterDecisions(db *gorm.DB, letterIDs []uint) (map[uint]letterDecision, error) {
+	latest := db.Model(&models.LetterStatusHistory{}).Select("MAX(id)").
+		Where("letter_id IN ? AND new_status IN ?", letterIDs, []string{workflow.StatusAccepted, workflow.StatusRejected}).
+		Group("letter_id")
+
+	var rows []letterDecision
+	err := db.Table("letter_status_histories").
+		Select("letter_status_histories.letter_id, letter_status_histories.created_at AS decided_at, users.name AS reviewer").
+		Joins("LEFT JOIN users ON users.id = letter_status_histories.actor_id").
+		Where("letter_status_histories.id IN (?)", latest).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	decisions := make(map[uint]letterDecision, len(rows))
+	for _, row := range rows {
+		decisions[row.LetterID] = row
+	}
+	return decisions, nil
+}
+
+// letterExportRow menyusun satu baris export dari surat & keputusannya
+func letterExportRow(letter models.Letter, decision letterDecision, decided bool) []string {
+	number := ""
+	if letter.Number != nil {
+		number = *letter.Number
+	}
+
+	// Surat yang belum diputuskan menampilkan reviewer yang ditugaskan
+	reviewer, decidedAt := "", ""
+	if decided {
+		reviewer = decision.Reviewer
+		decidedAt = decision.DecidedAt.Local().Format(exportTimeLayout)
+	} else if letter.AssignedReviewer != nil {
+		reviewer = letter.AssignedReviewer.Name
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(letter.ID), 10),
+		number,
+		letter.CreatedAt.Local().Format(exportTimeLayout),
+		letter.User.Name,
+		letter.User.Email,
+		letter.LetterType.Name,
+		letter.Subject,
+		letter.Status,
+		reviewer,
+		decidedAt,
+		letter.RejectReason,
+	}
+}
+
+// isDecided mengecek apakah status surat berasal dari keputusan review
+func isDecided(status string) bool {
+	return status == workflow.StatusAccepted || status == workflow.StatusRejected || status == workflow.StatusArchived
+}
+
+// streamLetterExport menulis surat hasil query per batch sesuai urutan order.
+// Batch diambil dengan offset (bukan FindInBatches) supaya urutan pilihan user tetap terjaga.
+func streamLetterExport(w export.RowWriter, query *gorm.DB, order string) error {
+	query = preloadLetterList(query).Preload("AssignedReviewer").
+		Order(order).Session(&gorm.Session{})
+
+	for offset := 0; ; offset += exportBatchSize {
+		var batch []models.Letter
+		if err := query.Limit(exportBatchSize).Offset(offset).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(batch))
+		for i, letter := range batch {
+			ids[i] = letter.ID
+		}
+		decisions, err := loadLetterDecisions(config.DB, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, letter := range batch {
+			decision, ok := decisions[letter.ID]
+			if err := w.WriteRow(letterExportRow(letter, decision, ok && isDecided(letter.Status))); err != nil {
+				return err
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// ExportLetters godoc
+// @Summary Export letters to CSV / XLSX
+// @Description Unduh daftar surat untuk laporan dengan filter yang sama seperti daftar surat. Data di-stream per batch sehingga aman untuk data besar.
+// @Tags Letters
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "Format file: csv atau xlsx" default(csv)
+// @Param sort query string false "Urutan, misal -created_at atau status,-id" default(-created_at)
+// @Param status query string false "Filter status, pisahkan dengan koma"
+// @Param type_id query int false "Filter jenis surat"
+// @Param user_id query int false "Filter pemohon"
+// @Param created_from query string false "Tanggal dibuat dari (YYYY-MM-DD)"
+// @Param created_to query string false "Tanggal dibuat sampai (YYYY-MM-DD)"
+// @Param overdue query bool false "Hanya surat yang melewati tenggat review"
+// @Param q query string false "Cari di nama pemohon, perihal, isi & nomor surat"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Router /letters/export [get]
+func ExportLetters(c *gin.Context) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format export harus csv atau xlsx"})
+		return
+	}
+	params, err := letterListSpec.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, err := parseLetterFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("surat-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	w, err := export.New(format, c.Writer, "Surat")
+	if err != nil {
+		log.Println("Gagal membuat file export:", err)
+		return
+	}
+	if err := w.WriteRow(letterExportHeader); err != nil {
+		log.Println("Gagal menulis export:", err)
+		return
+	}
+
+	// Response sudah mulai dikirim, jadi error di tengah hanya bisa dicatat di log
+	if err := streamLetterExport(w, letterListQuery(config.DB, currentActor(c), filter), params.Order); err != nil {
+		log.Println("Gagal export surat:", err)
+	}
+
+	if err := w.Close(); err != nil {
+		log.Println("Gagal menutup file export:", err)
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"net/http"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// currentActor mengambil user_id & role yang disimpan AuthMiddleware
+func currentActor(c *gin.Context) policy.Actor {
+	uid, _ := c.Get("user_id")
+	role, _ := c.Get("role")
+	id, _ := uid.(uint)
+	name, _ := role.(string)
+	return policy.Actor{ID: id, Role: name}
+}
+
+// authorizeLetter mengecek aturan akses surat di package policy.
+// Mengirim response 403 dan mengembalikan false kalau ditolak.
+func authorizeLetter(c *gin.Context, action policy.Action, letter *models.Letter) bool {
+	if err := policy.Authorize(currentActor(c), action, letter); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// recordLetterHistory menyimpan setiap langkah perubahan status ke riwayat.
+// Alasan hanya ditempel di langkah terakhir (langkah keputusan).
+func recordLetterHistory(tx *gorm.DB, letterID uint, by policy.Actor, changes []statusChange, reason string) error {
+	for i, change := range changes {
+		entry := models.LetterStatusHistory{
+			LetterID:  letterID,
+			ActorID:   by.ID,
+			ActorRole: by.Role,
+			OldStatus: change.From,
+			NewStatus: change.To,
+		}
+		if i == len(changes)-1 {
+			entry.Reason = reason
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLetterHistory godoc
+// @Summary Get letter status history
+// @Description Ambil riwayat perubahan status surat (timeline). User hanya bisa melihat surat miliknya.
+// @Tags Letters
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Success 200 {array} models.LetterStatusHistory
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/history [get]
+func GetLetterHistory(c *gin.Context) {
+	by := currentActor(c)
+
+	// Admin tetap bisa melihat riwayat surat yang sudah dihapus
+	var letter models.Letter
+	if err := config.DB.First(&letter, c.Param("id")).Error; err != nil && by.Role != policy.RoleAdmin {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+
+	if letter.ID != 0 && !authorizeLetter(c, policy.ActionView, &letter) {
+		return
+	}
+
+	var history []models.LetterStatusHistory
+	config.DB.Where("letter_id = ?", c.Param("id")).Order("created_at, id").Find(&history)
+	c.JSON(http.StatusOK, history)
+}
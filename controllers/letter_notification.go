@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/notification"
+	"sanbercode-golang-batch-70-final-project/outbox"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"gorm.io/gorm"
+)
+
+// Semua notifikasi ditulis ke outbox di dalam transaksi (tx) yang sama dengan perubahan
+// surat; pengiriman ke Telegram / WhatsApp dilakukan worker outbox setelah commit.
+// letterID 0 berarti pesan tidak terkait satu surat tertentu.
+
+// sendToSetting mengantrekan pesan untuk semua channel terdaftar yang diaktifkan user
+func sendToSetting(tx *gorm.DB, letterID uint, s models.Setting, message string, actions ...notification.Action) error {
+	return outbox.Enqueue(tx, letterID, s, message, actions...)
+}
+
+// noticeLetter memuat surat beserta relasi yang dipakai di pesan notifikasi.
+// Dipanggil di dalam transaksi supaya pesan memakai data yang baru disimpan.
+func noticeLetter(tx *gorm.DB, id uint) (models.Letter, error) {
+	var letter models.Letter
+	err := tx.Preload("User").Preload("LetterType").Preload("AssignedReviewer").First(&letter, id).Error
+	return letter, err
+}
+
+// notifyReviewers mengirim pesan ke semua reviewer yang mengaktifkan notifikasi
+func notifyReviewers(tx *gorm.DB, letterID uint, message string, actions ...notification.Action) error {
+	return notifyRole(tx, letterID, policy.RoleReviewer, message, actions...)
+}
+
+// notifyRole mengirim pesan ke semua user dengan role tertentu
+func notifyRole(tx *gorm.DB, letterID uint, role, message string, actions ...notification.Action) error {
+	var settings []models.Setting
+	if err := tx.Select("settings.*").
+		Joins("JOIN users ON users.id = settings.user_id").
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("roles.name = ?", role).Find(&settings).Error; err != nil {
+		return err
+	}
+
+	for _, s := range settings {
+		if err := sendToSetting(tx, letterID, s, message, actions...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyLetterReviewers mengirim pesan ke reviewer yang ditugaskan pada surat,
+// atau ke semua reviewer kalau surat belum ditugaskan
+func notifyLetterReviewers(tx *gorm.DB, letter models.Letter, message string, actions ...notification.Action) error {
+	if letter.AssignedReviewerID != nil {
+		return notifyUser(tx, letter.ID, *letter.AssignedReviewerID, message, actions...)
+	}
+	return notifyReviewers(tx, letter.ID, message, actions...)
+}
+
+// stepApproverIDs mengembalikan user yang berhak memutuskan tahap persetujuan surat:
+// approver yang ditunjuk, reviewer yang ditugaskan (untuk tahap ber-role reviewer),
+// atau semua user dengan role tahap tersebut
+func stepApproverIDs(tx *gorm.DB, letter models.Letter, step models.ApprovalStep) []uint {
+	if step.UserID != nil {
+		return []uint{*step.UserID}
+	}
+	if step.Role == policy.RoleReviewer && letter.AssignedReviewerID != nil {
+		return []uint{*letter.AssignedReviewerID}
+	}
+
+	var ids []uint
+	tx.Model(&models.User{}).
+		Joins("JOIN roles ON roles.id = users.role_id").
+		Where("roles.name = ?", step.Role).Pluck("users.id", &ids)
+	return ids
+}
+
+// notifyStepApprovers mengirim pesan ke approver tahap persetujuan surat
+func notifyStepApprovers(tx *gorm.DB, letter models.Letter, step models.ApprovalStep, message string, actions ...notification.Action) error {
+	for _, id := range stepApproverIDs(tx, letter, step) {
+		if err := notifyUser(tx, letter.ID, id, message, actions...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyUser mengirim pesan ke satu user sesuai setting notifikasinya
+func notifyUser(tx *gorm.DB, letterID, userID uint, message string, actions ...notification.Action) error {
+	setting, ok, err := userSetting(tx, userID)
+	if !ok || err != nil {
+		return err
+	}
+	return sendToSetting(tx, letterID, setting, message, actions...)
+}
+
+// notifyUserSummary mengirim satu pesan ringkasan tentang beberapa surat ke satu user;
+// pesan ditautkan ke setiap surat supaya tampil di notifikasi masing-masing surat
+func notifyUserSummary(tx *gorm.DB, letterIDs []uint, userID uint, message string) error {
+	setting, ok, err := userSetting(tx, userID)
+	if !ok || err != nil {
+		return err
+	}
+	return outbox.EnqueueSummary(tx, letterIDs, setting, message)
+}
+
+// userSetting mengambil setting notifikasi user; ok false kalau user belum mengaturnya
+func userSetting(tx *gorm.DB, userID uint) (models.Setting, bool, error) {
+	var setting models.Setting
+	err := tx.Where("user_id = ?", userID).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return setting, false, nil
+	}
+	return setting, err == nil, err
+}
+
+// notifyNewLetter mengirim notifikasi pengajuan baru ke approver tahap pertama,
+// atau ke reviewer yang ditugaskan kalau jenis surat tidak punya rantai persetujuan
+func notifyNewLetter(tx *gorm.DB, letter models.Letter) error {
+	message := fmt.Sprintf("📩 Pengajuan surat baru dari *%s* untuk jenis surat *%s* (status: %s).",
+		letter.User.Name, letter.LetterType.Name, workflow.StatusSubmitted)
+
+	var first models.ApprovalStep
+	err := tx.Where("type_id = ?", letter.TypeID).Order("step_order").First(&first).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notifyLetterReviewers(tx, letter, message, reviewActions(letter.ID)...)
+	}
+	if err != nil {
+		return err
+	}
+	return notifyStepApprovers(tx, letter, first, message+fmt.Sprintf("\nMenunggu persetujuan tahap 1 (%s).", first.Name),
+		reviewActions(letter.ID)...)
+}
+
+// notifyCurrentApprovers mengirim pesan ke approver yang sedang ditunggu keputusannya
+// (tahap pertama untuk surat yang baru diajukan ulang)
+func notifyCurrentApprovers(tx *gorm.DB, letter models.Letter, message string, actions ...notification.Action) error {
+	var steps []models.ApprovalStep
+	if err := tx.Where("type_id = ?", letter.TypeID).Order("step_order").Find(&steps).Error; err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return notifyLetterReviewers(tx, letter, message, actions...)
+	}
+
+	current := letter.CurrentStep
+	if current < 1 {
+		current = 1
+	}
+	if current > len(steps) {
+		current = len(steps)
+	}
+	return notifyStepApprovers(tx, letter, steps[current-1], message, actions...)
+}
+
+// notifyReviewReminder mengingatkan approver bahwa surat sudah melewati tenggat review
+func notifyReviewReminder(tx *gorm.DB, letter models.Letter) error {
+	message := fmt.Sprintf("⏰ Surat *%s* dari *%s* sudah melewati tenggat review (%s). Mohon segera diputuskan.",
+		letter.LetterType.Name, letter.User.Name, formatDeadline(letter.DueAt))
+	return notifyCurrentApprovers(tx, letter, message)
+}
+
+// notifyEscalation meneruskan surat yang terlalu lama tertahan ke semua admin
+func notifyEscalation(tx *gorm.DB, letter models.Letter) error {
+	message := fmt.Sprintf("🚨 Eskalasi: surat *%s* dari *%s* belum diputuskan sejak %s (tenggat %s).",
+		letter.LetterType.Name, letter.User.Name, letter.CreatedAt.Format(deadlineLayout), formatDeadline(letter.DueAt))
+	if letter.AssignedReviewer != nil {
+		message += fmt.Sprintf("\nReviewer: %s", letter.AssignedReviewer.Name)
+	}
+	return notifyRole(tx, letter.ID, policy.RoleAdmin, message)
+}
+
+// deadlineLayout adalah format tanggal & jam tenggat di pesan notifikasi
+const deadlineLayout = "02-01-2006 15:04"
+
+func formatDeadline(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(deadlineLayout)
+}
+
+// notifyNewComment memberi tahu pihak lawan bicara saat ada komentar baru:
+// reviewer kalau pemohon yang bertanya, pemohon kalau reviewer / admin menjawab.
+// Komentar internal hanya diteruskan ke reviewer yang ditugaskan.
+func notifyNewComment(tx *gorm.DB, letter models.Letter, comment models.LetterComment, by policy.Actor) error {
+	message := fmt.Sprintf("💬 Komentar baru dari *%s* pada surat *%s*:\n%s",
+		comment.User.Name, letter.LetterType.Name, comment.Body)
+
+	switch {
+	case by.IsOwner(&letter):
+		if workflow.IsPending(letter.Status) {
+			return notifyCurrentApprovers(tx, letter, message)
+		}
+		return notifyLetterReviewers(tx, letter, message)
+	case !comment.Internal:
+		return notifyUser(tx, letter.ID, letter.UserID, message)
+	case letter.AssignedReviewerID != nil && *letter.AssignedReviewerID != by.ID:
+		return notifyUser(tx, letter.ID, *letter.AssignedReviewerID, "🔒 (internal) "+message)
+	}
+	return nil
+}
+
+// notifyLetterAssigned memberi tahu reviewer bahwa surat ditugaskan kepadanya
+func notifyLetterAssigned(tx *gorm.DB, letter models.Letter) error {
+	if letter.AssignedReviewerID == nil {
+		return nil
+	}
+	message := fmt.Sprintf("📌 Surat *%s* dari *%s* ditugaskan kepadamu untuk ditinjau (status: %s).",
+		letter.LetterType.Name, letter.User.Name, letter.Status)
+	return notifyUser(tx, letter.ID, *letter.AssignedReviewerID, message)
+}
+
+// notifyReviewOutcome memberi tahu hasil review: approver tahap berikutnya kalau
+// rantai persetujuan belum selesai, atau pemilik surat kalau sudah ada keputusan akhir
+func notifyReviewOutcome(tx *gorm.DB, letter models.Letter, outcome *reviewOutcome) error {
+	if outcome != nil && outcome.NextStep != nil {
+		message := fmt.Sprintf("📝 Surat *%s* dari *%s* menunggu persetujuan tahap %d (%s).",
+			letter.LetterType.Name, letter.User.Name, letter.CurrentStep, outcome.NextStep.Name)
+		return notifyStepApprovers(tx, letter, *outcome.NextStep, message, reviewActions(letter.ID)...)
+	}
+	return notifyStatusChange(tx, letter)
+}
+
+// notifyLetterCancelled memberi tahu approver tahap yang sedang berjalan bahwa pengajuan ditarik pemiliknya
+func notifyLetterCancelled(tx *gorm.DB, letter models.Letter) error {
+	message := fmt.Sprintf("🚫 Pengajuan surat *%s* dari *%s* dibatalkan oleh pemohon.\nAlasan: %s",
+		letter.LetterType.Name, letter.User.Name, letter.CancelReason)
+	return notifyCurrentApprovers(tx, letter, message)
+}
+
+// notifyLetterResubmitted memberi tahu approver tahap pertama bahwa surat yang ditolak sudah direvisi.
+// Tombol Terima / Tolak hanya dikirim ke approver yang memang boleh memutuskan tahap tersebut.
+func notifyLetterResubmitted(tx *gorm.DB, letter models.Letter) error {
+	message := fmt.Sprintf("🔁 Pengajuan ulang surat *%s* dari *%s* (revisi %d), silakan ditinjau kembali.",
+		letter.LetterType.Name, letter.User.Name, letter.Revision)
+	return notifyCurrentApprovers(tx, letter, message, reviewActions(letter.ID)...)
+}
+
+// notifyStatusChange mengirim status terbaru surat ke pemiliknya
+func notifyStatusChange(tx *gorm.DB, letter models.Letter) error {
+	message := fmt.Sprintf("📢 Status surat kamu (%s) kini: *%s*.",
+		letter.LetterType.Name, letter.Status)
+
+	if letter.Status == workflow.StatusRejected && letter.RejectReason != "" {
+		message += fmt.Sprintf("\nAlasan: %s", letter.RejectReason)
+	}
+	if letter.Status == workflow.StatusAccepted && letter.Number != nil {
+		message += fmt.Sprintf("\nNomor surat: %s", *letter.Number)
+	}
+	if letter.Status == workflow.StatusCancelled && letter.CancelReason != "" {
+		message += fmt.Sprintf("\nAlasan: %s", letter.CancelReason)
+	}
+	return notifyUser(tx, letter.ID, letter.UserID, message)
+}
+
+// notifyBulkDecisions mengirim satu pesan ringkasan per user setelah keputusan massal:
+// pemilik surat menerima daftar status terbaru suratnya, approver tahap berikutnya
+// menerima daftar surat yang menunggu persetujuannya. Pesan ditautkan ke setiap surat di dalamnya.
+func notifyBulkDecisions(tx *gorm.DB, letters []models.Letter, outcomes map[uint]*reviewOutcome) error {
+	type summary struct {
+		decided   []string
+		waiting   []string
+		letterIDs []uint
+	}
+	summaries := map[uint]*summary{}
+	var order []uint // urutan penerima supaya pesan terkirim deterministik
+	get := func(userID uint) *summary {
+		if summaries[userID] == nil {
+			summaries[userID] = &summary{}
+			order = append(order, userID)
+		}
+		return summaries[userID]
+	}
+
+	for _, letter := range letters {
+		if outcome := outcomes[letter.ID]; outcome != nil && outcome.NextStep != nil {
+			line := fmt.Sprintf("- %s dari %s (tahap %d: %s)",
+				letter.LetterType.Name, letter.User.Name, letter.CurrentStep, outcome.NextStep.Name)
+			for _, id := range stepApproverIDs(tx, letter, *outcome.NextStep) {
+				s := get(id)
+				s.waiting = append(s.waiting, line)
+				s.letterIDs = append(s.letterIDs, letter.ID)
+			}
+			continue
+		}
+
+		line := fmt.Sprintf("- %s \"%s\": *%s*", letter.LetterType.Name, letter.Subject, letter.Status)
+		if letter.Status == workflow.StatusRejected && letter.RejectReason != "" {
+			line += fmt.Sprintf(" (alasan: %s)", letter.RejectReason)
+		}
+		if letter.Status == workflow.StatusAccepted && letter.Number != nil {
+			line += fmt.Sprintf(" (nomor: %s)", *letter.Number)
+		}
+		s := get(letter.UserID)
+		s.decided = append(s.decided, line)
+		s.letterIDs = append(s.letterIDs, letter.ID)
+	}
+
+	for _, userID := range order {
+		s := summaries[userID]
+		var parts []string
+		if len(s.decided) > 0 {
+			parts = append(parts, fmt.Sprintf("📢 Status %d surat kamu diperbarui:\n%s", len(s.decided), strings.Join(s.decided, "\n")))
+		}
+		if len(s.waiting) > 0 {
+			parts = append(parts, fmt.Sprintf("📝 %d surat menunggu persetujuanmu:\n%s", len(s.waiting), strings.Join(s.waiting, "\n")))
+		}
+		if err := notifyUserSummary(tx, s.letterIDs, userID, strings.Join(parts, "\n\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/listing"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// letterListSpec adalah field yang boleh dipakai di parameter sort daftar surat.
+// Filter & pencarian surat ditangani letterFilter karena butuh validasi & join.
+var letterListSpec = listing.Spec{
+	Sortable: map[string]string{
+		"id":         "letters.id",
+		"created_at": "letters.created_at",
+		"updated_at": "letters.updated_at",
+		"status":     "letters.status",
+		"subject":    "letters.subject",
+		"due_at":     "letters.due_at",
+		"issued_at":  "letters.issued_at",
+	},
+	DefaultSort: "-created_at",
+}
+
+// LetterListResponse adalah satu halaman daftar surat beserta metadata halamannya
+type LetterListResponse struct {
+	Items  []models.Letter `json:"items"`
+	Paging listing.Paging  `json:"paging"`
+}
+
+// letterFilter adalah filter daftar surat dari query string
+type letterFilter struct {
+	Statuses    []string
+	TypeID      uint
+	UserID      uint
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time // eksklusif (awal hari setelah created_to)
+	Overdue     bool
+	Search      string
+}
+
+// parseLetterFilter membaca status, type_id, user_id, created_from, created_to, overdue & q
+func parseLetterFilter(c *gin.Context) (letterFilter, error) {
+	f := letterFilter{
+		Overdue: c.Query("overdue") == "true",
+		Search:  strings.TrimSpace(c.Query("q")),
+	}
+
+	if v := c.Query("status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			s = strings.TrimSpace(s)
+			if !workflow.IsValid(s) {
+				return f, fmt.Errorf("Status '%s' tidak dikenali", s)
+			}
+			f.Statuses = append(f.Statuses, s)
+		}
+	}
+
+	var err error
+	if f.TypeID, err = parseIDQuery(c, "type_id"); err != nil {
+		return f, err
+	}
+	if f.UserID, err = parseIDQuery(c, "user_id"); err != nil {
+		return f, err
+	}
+	if f.CreatedFrom, err = parseDateQuery(c, "created_from"); err != nil {
+		return f, err
+	}
+	if f.CreatedTo, err = parseDateQuery(c, "created_to"); err != nil {
+		return f, err
+	}
+	if f.CreatedTo != nil {
+		end := f.CreatedTo.AddDate(0, 0, 1)
+		f.CreatedTo = &end
+	}
+	return f, nil
+}
+
+// parseIDQuery membaca parameter ID opsional (0 kalau kosong)
+func parseIDQuery(c *gin.Context, name string) (uint, error) {
+	v := c.Query(name)
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("Parameter %s harus berupa ID", name)
+	}
+	return uint(id), nil
+}
+
+// parseDateQuery membaca parameter tanggal opsional berformat YYYY-MM-DD
+func parseDateQuery(c *gin.Context, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("Parameter %s harus berformat YYYY-MM-DD", name)
+	}
+	return &t, nil
+}
+
+// apply menerapkan filter ke query surat
+func (f letterFilter) apply(db *gorm.DB) *gorm.DB {
+	if len(f.Statuses) > 0 {
+		db = db.Where("letters.status IN ?", f.Statuses)
+	}
+	if f.TypeID != 0 {
+		db = db.Where("letters.type_id = ?", f.TypeID)
+	}
+	if f.UserID != 0 {
+		db = db.Where("letters.user_id = ?", f.UserID)
+	}
+	if f.CreatedFrom != nil {
+		db = db.Where("letters.created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		db = db.Where("letters.created_at < ?", *f.CreatedTo)
+	}
+	if f.Overdue {
+		db = db.Where("letters.status IN ? AND letters.due_at < ?", pendingStatuses, time.Now())
+	}
+	if f.Search != "" {
+		// cari di nama pemohon & isi surat
+		like := listing.LikePattern(f.Search)
+		db = db.Joins("JOIN users ON users.id = letters.user_id").
+			Where("users.name LIKE ? OR letters.subject LIKE ? OR letters.purpose LIKE ? OR letters.body LIKE ? OR letters.number LIKE ?",
+				like, like, like, like, like)
+	}
+	return db
+}
+
+// preloadLetterList memilih kolom surat saja (query bisa join users) & memuat relasinya
+func preloadLetterList(db *gorm.DB) *gorm.DB {
+	return db.Select("letters.*").Preload("User.Role").Preload("LetterType")
+}
+
+// letterListQuery menyusun query surat yang boleh dilihat actor dengan filter f
+func letterListQuery(db *gorm.DB, by policy.Actor, f letterFilter) *gorm.DB {
+	query := policy.LetterScope(by)(db.Model(&models.Letter{}))
+	return f.apply(query)
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"fmt"
+
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"gorm.io/gorm"
+)
+
+// reviewOutcome adalah hasil keputusan review atas satu surat
+type reviewOutcome struct {
+	Approval *models.LetterApproval // nil kalau jenis surat tidak punya rantai persetujuan
+	NextStep *models.ApprovalStep   // tahap berikutnya yang perlu diberi tahu
+}
+
+// reviewLetter menerapkan keputusan review (in_review / accepted / rejected) di dalam transaksi tx.
+// Kalau jenis surat punya rantai persetujuan, "accepted" hanya menyetujui tahap saat ini;
+// surat baru benar-benar accepted setelah tahap terakhir disetujui.
+// Dipakai oleh semua jalur review supaya aturannya sama.
+func reviewLetter(tx *gorm.DB, by policy.Actor, letter *models.Letter, status, reason string) (*reviewOutcome, error) {
+	if err := policy.Authorize(by, policy.ActionReview, letter); err != nil {
+		return nil, err
+	}
+	if err := policy.AuthorizeStatus(by, status); err != nil {
+		return nil, err
+	}
+
+	outcome := &reviewOutcome{}
+	target := status
+	historyReason := reason
+
+	var steps []models.ApprovalStep
+	if status == workflow.StatusAccepted || status == workflow.StatusRejected {
+		if err := tx.Where("type_id = ?", letter.TypeID).Order("step_order").Find(&steps).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var step models.ApprovalStep
+	current := 0
+	if len(steps) > 0 {
+		current = letter.CurrentStep
+		if current < 1 {
+			current = 1
+		}
+		if current > len(steps) {
+			current = len(steps)
+		}
+		step = steps[current-1]
+		if err := policy.AuthorizeStep(by, step); err != nil {
+			return nil, err
+		}
+
+		// Tahap tengah yang disetujui: surat tetap in_review dan lanjut ke tahap berikutnya
+		if status == workflow.StatusAccepted && current < len(steps) {
+			target = workflow.StatusInReview
+			outcome.NextStep = &steps[current]
+			historyReason = fmt.Sprintf("Tahap %d (%s) disetujui", current, step.Name)
+			if reason != "" {
+				historyReason += ": " + reason
+			}
+		}
+	}
+
+	// Tahap berbasis role (atau surat tanpa rantai) hanya boleh diputuskan reviewer yang ditugaskan
+	if step.UserID == nil {
+		if err := policy.AuthorizeAssignee(by, letter); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []statusChange
+	if target != letter.Status {
+		var err error
+		if changes, err = changeLetterStatus(letter, target, reason); err != nil {
+			return nil, err
+		}
+	} else if !workflow.IsPending(letter.Status) {
+		return nil, &workflow.TransitionError{From: letter.Status, To: status}
+	} else {
+		// persetujuan tahap tengah tanpa perpindahan status tetap dicatat
+		changes = []statusChange{{From: letter.Status, To: letter.Status}}
+	}
+
+	if len(steps) > 0 {
+		approval := models.LetterApproval{
+			LetterID:     letter.ID,
+			Revision:     letter.Revision,
+			StepOrder:    step.StepOrder,
+			StepName:     step.Name,
+			ApproverID:   by.ID,
+			ApproverRole: by.Role,
+			Decision:     status,
+			Reason:       reason,
+		}
+		if err := tx.Create(&approval).Error; err != nil {
+			return nil, err
+		}
+		outcome.Approval = &approval
+
+		letter.CurrentStep = current
+		if outcome.NextStep != nil {
+			letter.CurrentStep = current + 1
+		}
+	}
+
+	if err := issueLetter(tx, letter); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(letter).
+		Select("status", "reject_reason", "current_step", "number", "issued_at", "verification_token").
+		Updates(letter).Error; err != nil {
+		return nil, err
+	}
+	if err := recordLetterHistory(tx, letter.ID, by, changes, historyReason); err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
+
+// orderApprovals mengurutkan preload riwayat persetujuan sesuai urutan keputusan
+func orderApprovals(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LetterResubmitInput digunakan untuk merevisi surat yang ditolak.
+// Field yang tidak dikirim tetap memakai isi versi sebelumnya.
+type LetterResubmitInput struct {
+	Subject *string        `json:"subject,omitempty" example:"Surat keterangan aktif kuliah (revisi)" binding:"omitempty,max=200"`
+	Purpose *string        `json:"purpose,omitempty" example:"Syarat pengajuan beasiswa"`
+	Body    *string        `json:"body,omitempty" example:"Sudah dilengkapi sesuai catatan reviewer"`
+	Fields  models.JSONMap `json:"fields,omitempty" swaggertype:"object"`
+}
+
+// ResubmitLetter godoc
+// @Summary Resubmit a rejected letter
+// @Description Pemilik surat merevisi isi surat yang ditolak lalu mengirimnya kembali ke reviewer.
+// @Description Isi & alasan penolakan versi sebelumnya disimpan sebagai revisi.
+// @Tags Letters
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Param request body LetterResubmitInput true "Isi surat yang direvisi"
+// @Success 200 {object} models.Letter
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /letters/{id}/resubmit [post]
+func ResubmitLetter(c *gin.Context) {
+	by := currentActor(c)
+
+	var letter models.Letter
+	if err := config.DB.Preload("User").Preload("LetterType").
+		First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+	if !authorizeLetter(c, policy.ActionResubmit, &letter) {
+		return
+	}
+	if letter.Status != workflow.StatusRejected {
+		c.JSON(http.StatusConflict, gin.H{"error": "Hanya surat yang ditolak yang bisa diajukan ulang"})
+		return
+	}
+
+	var input LetterResubmitInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Simpan versi lama sebelum isi surat ditimpa
+	previous := models.LetterRevision{
+		LetterID:     letter.ID,
+		Revision:     letter.Revision,
+		TypeID:       letter.TypeID,
+		Subject:      letter.Subject,
+		Purpose:      letter.Purpose,
+		Body:         letter.Body,
+		Fields:       letter.Fields,
+		RejectReason: letter.RejectReason,
+	}
+
+	if input.Subject != nil {
+		letter.Subject = *input.Subject
+	}
+	if input.Purpose != nil {
+		letter.Purpose = *input.Purpose
+	}
+	if input.Body != nil {
+		letter.Body = *input.Body
+	}
+	if input.Fields != nil {
+		letter.Fields = input.Fields
+	}
+	if err := validateLetterContent(&letter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validateLetterFields(c, letter.LetterType, letter.Fields) {
+		return
+	}
+
+	oldStatus := letter.Status
+	changes, err := changeLetterStatus(&letter, workflow.StatusSubmitted, "")
+	if err != nil {
+		respondStatusError(c, err)
+		return
+	}
+	letter.RejectReason = ""
+	letter.Revision++
+	letter.CurrentStep = 0 // rantai persetujuan diulang dari tahap pertama
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockLetter(tx, letter.ID, oldStatus); err != nil {
+			return err
+		}
+		if err := tx.Create(&previous).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&letter).Updates(map[string]interface{}{
+			"subject":       letter.Subject,
+			"purpose":       letter.Purpose,
+			"body":          letter.Body,
+			"fields":        letter.Fields,
+			"status":        letter.Status,
+			"reject_reason": letter.RejectReason,
+			"revision":      letter.Revision,
+			"current_step":  letter.CurrentStep,
+		}).Error; err != nil {
+			return err
+		}
+		if err := assignReviewer(tx, &letter); err != nil {
+			return err
+		}
+		if err := startReviewClock(tx, &letter); err != nil {
+			return err
+		}
+		reason := fmt.Sprintf("Pengajuan ulang (revisi %d)", letter.Revision)
+		if err := recordLetterHistory(tx, letter.ID, by, changes, reason); err != nil {
+			return err
+		}
+		notice, err := noticeLetter(tx, letter.ID)
+		if err != nil {
+			return err
+		}
+		return notifyLetterResubmitted(tx, notice)
+	})
+	if err != nil {
+		respondTxError(c, err, "Gagal mengajukan ulang surat")
+		return
+	}
+
+	config.DB.Preload("User.Role").Preload("LetterType").First(&letter, letter.ID)
+	c.JSON(http.StatusOK, letter)
+}
+
+// GetLetterRevisions godoc
+// @Summary Get letter revisions
+// @Description Ambil isi surat versi-versi sebelumnya beserta alasan penolakannya
+// @Tags Letters
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Letter ID"
+// @Success 200 {array} models.LetterRevision
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /letters/{id}/revisions [get]
+func GetLetterRevisions(c *gin.Context) {
+	var letter models.Letter
+	if err := config.DB.First(&letter, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Letter not found"})
+		return
+	}
+	if !authorizeLetter(c, policy.ActionView, &letter) {
+		return
+	}
+
+	var revisions []models.LetterRevision
+	config.DB.Where("letter_id = ?", letter.ID).Order("revision").Find(&revisions)
+	c.JSON(http.StatusOK, revisions)
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/sla"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"gorm.io/gorm"
+)
+
+// defaultSLACheckInterval dipakai kalau SLA_CHECK_INTERVAL kosong / tidak valid
+const defaultSLACheckInterval = 5 * time.Minute
+
+// pendingStatuses adalah status surat yang masih menunggu keputusan
+var pendingStatuses = []string{workflow.StatusSubmitted, workflow.StatusInReview}
+
+// startReviewClock menghitung ulang tenggat review & batas eskalasi saat surat dikirim
+// (atau diajukan ulang) sesuai SLA jenis suratnya, dalam jam kerja.
+func startReviewClock(tx *gorm.DB, letter *models.Letter) error {
+	var letterType models.LetterType
+	if err := tx.First(&letterType, letter.TypeID).Error; err != nil {
+		return err
+	}
+
+	letter.DueAt, letter.EscalateAt = nil, nil
+	letter.ReminderSentAt, letter.EscalatedAt = nil, nil
+	if letterType.ReviewSLAHours > 0 {
+		cal := sla.DefaultCalendar()
+		now := time.Now()
+		due := cal.Add(now, letterType.ReviewSLAHours)
+		letter.DueAt = &due
+		if letterType.EscalationHours > 0 {
+			escalate := cal.Add(now, letterType.EscalationHours)
+			letter.EscalateAt = &escalate
+		}
+	}
+
+	return tx.Model(letter).Updates(map[string]interface{}{
+		"due_at":           letter.DueAt,
+		"escalate_at":      letter.EscalateAt,
+		"reminder_sent_at": nil,
+		"escalated_at":     nil,
+	}).Error
+}
+
+// ===============================
+// Scheduler SLA
+// ===============================
+
+// StartSLAScheduler menjalankan goroutine yang secara berkala mengirim pengingat
+// untuk surat yang lewat tenggat dan mengeskalasi ke admin setelah batas kedua.
+// Interval diatur lewat env SLA_CHECK_INTERVAL (misal "5m").
+func StartSLAScheduler() {
+	interval := defaultSLACheckInterval
+	if d, err := time.ParseDuration(os.Getenv("SLA_CHECK_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+
+	go func() {
+		checkLetterDeadlines(time.Now())
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			checkLetterDeadlines(now)
+		}
+	}()
+}
+
+// checkLetterDeadlines mengirim pengingat & eskalasi yang jatuh tempo pada waktu now.
+// Setiap surat hanya diingatkan / dieskalasi sekali per pengajuan.
+func checkLetterDeadlines(now time.Time) {
+	var due []models.Letter
+	if err := config.DB.Preload("User").Preload("LetterType").
+		Where("status IN ? AND due_at <= ? AND reminder_sent_at IS NULL", pendingStatuses, now).
+		Find(&due).Error; err != nil {
+		log.Println("Gagal mengambil surat lewat tenggat:", err)
+		return
+	}
+	for _, letter := range due {
+		sendDeadlineNotice(letter, "reminder_sent_at", now, notifyReviewReminder)
+	}
+
+	var late []models.Letter
+	if err := config.DB.Preload("User").Preload("LetterType").Preload("AssignedReviewer").
+		Where("status IN ? AND escalate_at <= ? AND escalated_at IS NULL", pendingStatuses, now).
+		Find(&late).Error; err != nil {
+		log.Println("Gagal mengambil surat untuk eskalasi:", err)
+		return
+	}
+	for _, letter := range late {
+		sendDeadlineNotice(letter, "escalated_at", now, notifyEscalation)
+	}
+}
+
+// sendDeadlineNotice menandai pengingat / eskalasi sudah dikirim dan mengantrekan
+// notifikasinya dalam satu transaksi. Hanya satu pemanggil yang berhasil menandai,
+// sehingga pesan tidak terkirim dua kali.
+func sendDeadlineNotice(letter models.Letter, column string, now time.Time, notify func(*gorm.DB, models.Letter) error) {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Letter{}).
+			Where("id = ? AND "+column+" IS NULL", letter.ID).
+			UpdateColumn(column, now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return notify(tx, letter)
+	})
+	if err != nil {
+		log.Println("Gagal memproses tenggat surat:", err)
+	}
+}
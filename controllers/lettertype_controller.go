@@ -1,30 +1,183 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
+	"sanbercode-golang-batch-70-final-project/assignment"
 	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/document"
+	"sanbercode-golang-batch-70-final-project/forms"
+	"sanbercode-golang-batch-70-final-project/listing"
 	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/numbering"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/sla"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // LetterTypeInput digunakan untuk create & update letter type
 type LetterTypeInput struct {
-	Name        string `json:"name" example:"Surat Test"`
-	Description string `json:"description" example:"Deskripsi surat Test"`
+	Name        string            `json:"name" example:"Surat Test"`
+	Description string            `json:"description" example:"Deskripsi surat Test"`
+	FormSchema  models.FormSchema `json:"form_schema"`
+	Template    string            `json:"template,omitempty" example:"Menerangkan bahwa {{.UserName}} ({{.Fields.nim}}) adalah mahasiswa aktif."`
+
+	NumberCode    string `json:"number_code" example:"SK"`
+	NumberFormat  string `json:"number_format,omitempty" example:"{seq}/{code}/{month_roman}/{year}"`
+	NumberReset   string `json:"number_reset,omitempty" example:"yearly"`
+	NumberPadding int    `json:"number_padding,omitempty" example:"3"`
+
+	AssignmentStrategy string `json:"assignment_strategy,omitempty" example:"round_robin"`
+
+	ReviewSLAHours  int `json:"review_sla_hours,omitempty" example:"48"`
+	EscalationHours int `json:"escalation_hours,omitempty" example:"72"`
+
+	ApprovalSteps []ApprovalStepInput `json:"approval_steps,omitempty"`
+}
+
+// ApprovalStepInput adalah satu tahap persetujuan, urutannya mengikuti urutan array.
+// Isi salah satu: role (semua user dengan role tsb) atau user_id (user tertentu).
+type ApprovalStepInput struct {
+	Name   string `json:"name" example:"Reviewer bagian"`
+	Role   string `json:"role,omitempty" example:"reviewer"`
+	UserID *uint  `json:"user_id,omitempty" example:"2"`
+}
+
+// approvalSteps mengubah input menjadi model dengan step_order berurutan mulai dari 1
+func (in LetterTypeInput) approvalSteps() []models.ApprovalStep {
+	steps := make([]models.ApprovalStep, 0, len(in.ApprovalSteps))
+	for i, s := range in.ApprovalSteps {
+		steps = append(steps, models.ApprovalStep{
+			StepOrder: i + 1,
+			Name:      s.Name,
+			Role:      s.Role,
+			UserID:    s.UserID,
+		})
+	}
+	return steps
+}
+
+// letterTypeInputFrom mengisi input dengan nilai jenis surat saat ini, supaya
+// update hanya mengganti field yang dikirim
+func letterTypeInputFrom(lt models.LetterType) LetterTypeInput {
+	input := LetterTypeInput{
+		Name:               lt.Name,
+		Description:        lt.Description,
+		FormSchema:         lt.FormSchema,
+		Template:           lt.Template,
+		NumberCode:         lt.NumberCode,
+		NumberFormat:       lt.NumberFormat,
+		NumberReset:        lt.NumberReset,
+		NumberPadding:      lt.NumberPadding,
+		AssignmentStrategy: lt.AssignmentStrategy,
+		ReviewSLAHours:     lt.ReviewSLAHours,
+		EscalationHours:    lt.EscalationHours,
+	}
+	for _, s := range lt.ApprovalSteps {
+		input.ApprovalSteps = append(input.ApprovalSteps, ApprovalStepInput{Name: s.Name, Role: s.Role, UserID: s.UserID})
+	}
+	return input
+}
+
+// validateApprovalSteps memastikan setiap tahap punya approver reviewer/admin yang valid
+func validateApprovalSteps(steps []ApprovalStepInput) forms.Errors {
+	errs := forms.Errors{}
+	for i, s := range steps {
+		key := fmt.Sprintf("approval_steps[%d]", i)
+		switch {
+		case s.Name == "":
+			errs[key] = "Nama tahap wajib diisi"
+		case (s.Role == "") == (s.UserID == nil):
+			errs[key] = "Isi salah satu: role atau user_id"
+		case s.Role != "" && s.Role != policy.RoleReviewer && s.Role != policy.RoleAdmin:
+			errs[key] = "Role approver harus reviewer atau admin"
+		case s.UserID != nil:
+			var user models.User
+			if err := config.DB.Preload("Role").First(&user, *s.UserID).Error; err != nil {
+				errs[key] = "User approver tidak ditemukan"
+			} else if user.Role.Name != policy.RoleReviewer && user.Role.Name != policy.RoleAdmin {
+				errs[key] = "User approver harus ber-role reviewer atau admin"
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateLetterTypeInput memeriksa form schema & template sebelum disimpan.
+// Mengirim response 400 dan mengembalikan false kalau tidak valid.
+func validateLetterTypeInput(c *gin.Context, input LetterTypeInput, id uint) bool {
+	// Kode nomor membedakan nomor surat antar jenis, jadi wajib & unik
+	if strings.TrimSpace(input.NumberCode) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kode nomor surat (number_code) wajib diisi"})
+		return false
+	}
+	var count int64
+	config.DB.Model(&models.LetterType{}).Where("number_code = ? AND id <> ?", input.NumberCode, id).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Kode nomor surat '%s' sudah dipakai jenis surat lain", input.NumberCode)})
+		return false
+	}
+	if errs := forms.ValidateSchema(input.FormSchema); errs != nil {
+		respondFieldErrors(c, "Form schema tidak valid", errs)
+		return false
+	}
+	if _, err := document.ParseTemplate(input.Template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Template surat tidak valid: " + err.Error()})
+		return false
+	}
+	if err := numbering.Validate(input.NumberFormat, input.NumberReset); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := assignment.Validate(input.AssignmentStrategy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := sla.Validate(input.ReviewSLAHours, input.EscalationHours); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if errs := validateApprovalSteps(input.ApprovalSteps); errs != nil {
+		respondFieldErrors(c, "Tahap persetujuan tidak valid", errs)
+		return false
+	}
+	return true
+}
+
+// preloadApprovalSteps memuat tahap persetujuan jenis surat sesuai urutannya
+func preloadApprovalSteps(db *gorm.DB) *gorm.DB {
+	return db.Preload("ApprovalSteps", orderApprovalSteps)
+}
+
+// orderApprovalSteps mengurutkan preload tahap persetujuan sesuai step_order
+func orderApprovalSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("step_order")
+}
+
+// respondFieldErrors mengirim 400 beserta pesan error per field
+func respondFieldErrors(c *gin.Context, message string, errs forms.Errors) {
+	c.JSON(http.StatusBadRequest, gin.H{"error": message, "fields": errs})
 }
 
 // CreateLetterType godoc
 // @Summary Create a new letter type
-// @Description Create a new letter type (admin only)
+// @Description Create a new letter type (admin only). number_code wajib diisi & unik antar jenis surat.
 // @Tags Letter Types
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body LetterTypeInput true "Letter Type input payload"
 // @Success 201 {object} models.LetterType
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]string
 // @Router /letter_types/ [post]
 func CreateLetterType(c *gin.Context) {
 	var input LetterTypeInput
@@ -33,26 +186,82 @@ func CreateLetterType(c *gin.Context) {
 		return
 	}
 
+	input.NumberCode = strings.TrimSpace(input.NumberCode)
+	if !validateLetterTypeInput(c, input, 0) {
+		return
+	}
+
 	lt := models.LetterType{
 		Name:        input.Name,
 		Description: input.Description,
+		FormSchema:  input.FormSchema,
+		Template:    input.Template,
+
+		NumberCode:         input.NumberCode,
+		NumberFormat:       input.NumberFormat,
+		NumberReset:        input.NumberReset,
+		NumberPadding:      input.NumberPadding,
+		AssignmentStrategy: input.AssignmentStrategy,
+		ReviewSLAHours:     input.ReviewSLAHours,
+		EscalationHours:    input.EscalationHours,
+		ApprovalSteps:      input.approvalSteps(),
+	}
+	if err := config.DB.Create(&lt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat letter type"})
+		return
 	}
-	config.DB.Create(&lt)
 	c.JSON(http.StatusCreated, lt)
 }
 
+// letterTypeListSpec adalah kemampuan daftar jenis surat (sort, filter & pencarian)
+var letterTypeListSpec = listing.Spec{
+	Sortable: map[string]string{
+		"id":   "letter_types.id",
+		"name": "letter_types.name",
+	},
+	DefaultSort: "id",
+	Filters: map[string]string{
+		"assignment_strategy": "letter_types.assignment_strategy",
+		"number_reset":        "letter_types.number_reset",
+	},
+	Searchable: []string{"letter_types.name", "letter_types.description", "letter_types.number_code"},
+}
+
+// LetterTypeListResponse adalah satu halaman daftar jenis surat
+type LetterTypeListResponse struct {
+	Items  []models.LetterType `json:"items"`
+	Paging listing.Paging      `json:"paging"`
+}
+
 // GetLetterTypes godoc
 // @Summary Get all letter types
-// @Description Get all letter types (admin only)
+// @Description Get all letter types per halaman (admin only)
 // @Tags Letter Types
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.LetterType
+// @Param page query int false "Halaman (mulai dari 1)"
+// @Param limit query int false "Jumlah per halaman (maks 100)"
+// @Param sort query string false "Urutan: id, name (awali - untuk menurun)" default(id)
+// @Param assignment_strategy query string false "Filter strategi pembagian reviewer"
+// @Param number_reset query string false "Filter reset nomor surat"
+// @Param q query string false "Cari di nama, deskripsi & kode nomor"
+// @Success 200 {object} LetterTypeListResponse
+// @Failure 400 {object} map[string]string
 // @Router /letter_types/ [get]
 func GetLetterTypes(c *gin.Context) {
-	var lts []models.LetterType
-	config.DB.Find(&lts)
-	c.JSON(http.StatusOK, lts)
+	params, err := letterTypeListSpec.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lts := []models.LetterType{}
+	paging, err := listing.Find(params.Apply(config.DB.Model(&models.LetterType{})), params, &lts, preloadApprovalSteps)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil letter type"})
+		return
+	}
+	c.JSON(http.StatusOK, LetterTypeListResponse{Items: lts, Paging: paging})
 }
 
 // GetLetterTypeByID godoc
@@ -67,7 +276,7 @@ func GetLetterTypes(c *gin.Context) {
 // @Router /letter_types/{id} [get]
 func GetLetterTypeByID(c *gin.Context) {
 	var lt models.LetterType
-	if err := config.DB.First(&lt, c.Param("id")).Error; err != nil {
+	if err := config.DB.Preload("ApprovalSteps", orderApprovalSteps).First(&lt, c.Param("id")).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Letter Type not found"})
 		return
 	}
@@ -76,7 +285,7 @@ func GetLetterTypeByID(c *gin.Context) {
 
 // UpdateLetterType godoc
 // @Summary Update letter type
-// @Description Update letter type (admin only)
+// @Description Update letter type (admin only). Field yang tidak dikirim tetap memakai nilai lama; approval_steps yang dikirim mengganti seluruh tahap.
 // @Tags Letter Types
 // @Accept json
 // @Produce json
@@ -84,24 +293,66 @@ func GetLetterTypeByID(c *gin.Context) {
 // @Param id path int true "Letter Type ID"
 // @Param request body LetterTypeInput true "Letter Type update payload"
 // @Success 200 {object} models.LetterType
+// @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Router /letter_types/{id} [put]
 func UpdateLetterType(c *gin.Context) {
 	var lt models.LetterType
-	if err := config.DB.First(&lt, c.Param("id")).Error; err != nil {
+	if err := preloadApprovalSteps(config.DB).First(&lt, c.Param("id")).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Letter Type not found"})
 		return
 	}
 
-	var input LetterTypeInput
+	// Field yang tidak dikirim tetap memakai nilai lama
+	input := letterTypeInputFrom(lt)
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	input.NumberCode = strings.TrimSpace(input.NumberCode)
+	if !validateLetterTypeInput(c, input, lt.ID) {
+		return
+	}
+
 	lt.Name = input.Name
 	lt.Description = input.Description
-	config.DB.Save(&lt)
+	lt.FormSchema = input.FormSchema
+	lt.Template = input.Template
+	lt.NumberCode = input.NumberCode
+	lt.NumberFormat = input.NumberFormat
+	lt.NumberReset = input.NumberReset
+	lt.NumberPadding = input.NumberPadding
+	lt.ReviewSLAHours = input.ReviewSLAHours
+	lt.EscalationHours = input.EscalationHours
+	if input.AssignmentStrategy != "" {
+		lt.AssignmentStrategy = input.AssignmentStrategy
+	}
+
+	// Tahap persetujuan lama diganti dengan daftar di input (daftar lama kalau tidak dikirim)
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(&lt).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("type_id = ?", lt.ID).Delete(&models.ApprovalStep{}).Error; err != nil {
+			return err
+		}
+		steps := input.approvalSteps()
+		for i := range steps {
+			steps[i].TypeID = lt.ID
+		}
+		if len(steps) > 0 {
+			return tx.Create(&steps).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update letter type"})
+		return
+	}
+
+	config.DB.Preload("ApprovalSteps", orderApprovalSteps).First(&lt, lt.ID)
 	c.JSON(http.StatusOK, lt)
 }
 
@@ -117,7 +368,16 @@ func UpdateLetterType(c *gin.Context) {
 // @Router /letter_types/{id} [delete]
 func DeleteLetterType(c *gin.Context) {
 	var lt models.LetterType
-	if err := config.DB.Delete(&lt, c.Param("id")).Error; err != nil {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("type_id = ?", c.Param("id")).Delete(&models.ApprovalStep{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("type_id = ?", c.Param("id")).Delete(&models.LetterTypeReviewer{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&lt, c.Param("id")).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Letter Type not found"})
 		return
 	}
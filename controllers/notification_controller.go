@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/listing"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/outbox"
+	"sanbercode-golang-batch-70-final-project/policy"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ==============================
+// OUTBOX NOTIFIKASI
+// ==============================
+
+// outboxListSpec adalah kemampuan daftar outbox notifikasi (sort, filter & pencarian)
+var outboxListSpec = listing.Spec{
+	Sortable: map[string]string{
+		"id":              "notification_outboxes.id",
+		"created_at":      "notification_outboxes.created_at",
+		"next_attempt_at": "notification_outboxes.next_attempt_at",
+		"attempts":        "notification_outboxes.attempts",
+	},
+	DefaultSort: "-id",
+	Filters: map[string]string{
+		"status":    "notification_outboxes.status",
+		"channel":   "notification_outboxes.channel",
+		"letter_id": "notification_outboxes.letter_id",
+		"user_id":   "notification_outboxes.user_id",
+	},
+	Searchable: []string{"notification_outboxes.recipient", "notification_outboxes.message", "notification_outboxes.last_error"},
+}
+
+// OutboxListResponse adalah satu halaman daftar outbox notifikasi
+type OutboxListResponse struct {
+	Items  []models.NotificationOutbox `json:"items"`
+	Paging listing.Paging              `json:"paging"`
+}
+
+// OutboxRetryResponse adalah jumlah notifikasi yang dikembalikan ke antrean
+type OutboxRetryResponse struct {
+	Requeued int64 `json:"requeued"`
+}
+
+// GetNotificationOutbox godoc
+// @Summary Get notification outbox
+// @Description Ambil antrean notifikasi per halaman (admin only). Pakai status=dead untuk melihat notifikasi yang gagal sampai batas percobaan.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Halaman (mulai dari 1)"
+// @Param limit query int false "Jumlah per halaman (maks 100)"
+// @Param sort query string false "Urutan: id, created_at, next_attempt_at, attempts (awali - untuk menurun)" default(-id)
+// @Param status query string false "Filter status (pending/processing/sent/dead), pisahkan dengan koma"
+// @Param channel query string false "Filter channel (telegram/whatsapp), pisahkan dengan koma"
+// @Param letter_id query string false "Filter surat, pisahkan dengan koma"
+// @Param user_id query string false "Filter user penerima, pisahkan dengan koma"
+// @Param q query string false "Cari di tujuan, isi pesan & pesan error"
+// @Success 200 {object} OutboxListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /notifications/outbox [get]
+func GetNotificationOutbox(c *gin.Context) {
+	params, err := outboxListSpec.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := []models.NotificationOutbox{}
+	paging, err := listing.Find(params.Apply(config.DB.Model(&models.NotificationOutbox{})), params, &items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil outbox notifikasi"})
+		return
+	}
+	c.JSON(http.StatusOK, OutboxListResponse{Items: items, Paging: paging})
+}
+
+// RetryNotification godoc
+// @Summary Re-drive a dead notification
+// @Description Kembalikan notifikasi berstatus dead ke antrean dengan jatah percobaan baru (admin only)
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Outbox ID"
+// @Success 200 {object} models.NotificationOutbox
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /notifications/outbox/{id}/retry [post]
+func RetryNotification(c *gin.Context) {
+	var item models.NotificationOutbox
+	if err := config.DB.First(&item, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notifikasi tidak ditemukan"})
+		return
+	}
+
+	if err := outbox.Retry(config.DB, item.ID); err != nil {
+		if errors.Is(err, outbox.ErrNotRetryable) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengantrekan ulang notifikasi"})
+		return
+	}
+
+	config.DB.First(&item, item.ID)
+	c.JSON(http.StatusOK, item)
+}
+
+// RetryDeadNotifications godoc
+// @Summary Re-drive all dead notifications
+// @Description Kembalikan semua notifikasi berstatus dead ke antrean (admin only), opsional hanya untuk satu channel
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param channel query string false "Hanya channel ini (telegram/whatsapp)"
+// @Success 200 {object} OutboxRetryResponse
+// @Router /notifications/outbox/retry [post]
+func RetryDeadNotifications(c *gin.Context) {
+	requeued, err := outbox.RetryDead(config.DB, c.Query("channel"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengantrekan ulang notifikasi"})
+		return
+	}
+	c.JSON(http.StatusOK, OutboxRetryResponse{Requeued: requeued})
+}
+
+// ==============================
+// LOG PENGIRIMAN NOTIFIKASI
+// ==============================
+
+// deliveryListSpec adalah kemampuan daftar log pengiriman notifikasi (sort, filter & pencarian)
+var deliveryListSpec = listing.Spec{
+	Sortable: map[string]string{
+		"id":         "notification_deliveries.id",
+		"started_at": "notification_deliveries.started_at",
+		"attempt":    "notification_deliveries.attempt",
+	},
+	DefaultSort: "-id",
+	Filters: map[string]string{
+		"status":    "notification_deliveries.status",
+		"channel":   "notification_deliveries.channel",
+		"letter_id": "notification_deliveries.letter_id",
+		"user_id":   "notification_deliveries.user_id",
+		"outbox_id": "notification_deliveries.outbox_id",
+	},
+	Searchable: []string{"notification_deliveries.recipient", "notification_deliveries.message",
+		"notification_deliveries.provider_message_id", "notification_deliveries.error"},
+}
+
+// DeliveryListResponse adalah satu halaman log pengiriman notifikasi
+type DeliveryListResponse struct {
+	Items  []models.NotificationDelivery `json:"items"`
+	Paging listing.Paging                `json:"paging"`
+}
+
+// GetNotificationDeliveries godoc
+// @Summary Get notification delivery log
+// @Description Ambil log setiap percobaan pengiriman notifikasi per halaman (admin only): channel, tujuan, surat, isi pesan, status, ID pesan dari provider & pesan error.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Halaman (mulai dari 1)"
+// @Param limit query int false "Jumlah per halaman (maks 100)"
+// @Param sort query string false "Urutan: id, started_at, attempt (awali - untuk menurun)" default(-id)
+// @Param status query string false "Filter status (sent/failed)"
+// @Param channel query string false "Filter channel (telegram/whatsapp), pisahkan dengan koma"
+// @Param letter_id query string false "Filter surat, pisahkan dengan koma"
+// @Param user_id query string false "Filter user penerima, pisahkan dengan koma"
+// @Param outbox_id query string false "Filter notifikasi outbox, pisahkan dengan koma"
+// @Param q query string false "Cari di tujuan, isi pesan, ID pesan provider & pesan error"
+// @Success 200 {object} DeliveryListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /notifications/deliveries [get]
+func GetNotificationDeliveries(c *gin.Context) {
+	params, err := deliveryListSpec.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := []models.NotificationDelivery{}
+	paging, err := listing.Find(params.Apply(config.DB.Model(&models.NotificationDelivery{})), params, &items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil log pengiriman notifikasi"})
+		return
+	}
+	c.JSON(http.StatusOK, DeliveryListResponse{Items: items, Paging: paging})
+}
+
+// orderDeliveries mengurutkan percobaan pengiriman dari yang pertama
+func orderDeliveries(db *gorm.DB) *gorm.DB {
+	return db.Order("attempt, id")
+}
+
+// loadLetterNotifications mengambil notifikasi surat (termasuk pesan ringkasan yang membahas
+// surat ini) beserta setiap percobaan pengirimannya. Selain admin, user hanya melihat notifikasi
+// yang dikirim kepadanya, karena tujuan (chat ID / nomor WhatsApp) & isi pesan milik penerima lain.
+func loadLetterNotifications(by policy.Actor, letter *models.Letter) error {
+	linked := config.DB.Model(&models.NotificationOutboxLetter{}).Select("outbox_id").Where("letter_id = ?", letter.ID)
+	query := config.DB.Where(config.DB.Where("letter_id = ?", letter.ID).Or("id IN (?)", linked))
+	if by.Role != policy.RoleAdmin {
+		query = query.Where("user_id = ?", by.ID)
+	}
+	letter.Notifications = []models.NotificationOutbox{}
+	return query.Preload("Deliveries", orderDeliveries).Order("id").Find(&letter.Notifications).Error
+}
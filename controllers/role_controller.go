@@ -4,6 +4,7 @@ import (
     "net/http"
 
     "sanbercode-golang-batch-70-final-project/config"
+    "sanbercode-golang-batch-70-final-project/listing"
     "sanbercode-golang-batch-70-final-project/models"
 
     "github.com/gin-gonic/gin"
@@ -35,18 +36,49 @@ func CreateRole(c *gin.Context) {
     c.JSON(http.StatusOK, role)
 }
 
+// roleListSpec adalah kemampuan daftar role (sort & pencarian)
+var roleListSpec = listing.Spec{
+    Sortable: map[string]string{
+        "id":   "roles.id",
+        "name": "roles.name",
+    },
+    DefaultSort: "id",
+    Searchable:  []string{"roles.name"},
+}
+
+// RoleListResponse adalah satu halaman daftar role
+type RoleListResponse struct {
+    Items  []models.Role  `json:"items"`
+    Paging listing.Paging `json:"paging"`
+}
+
 // GetRoles godoc
 // @Summary Get all roles
-// @Description Get list of all roles
+// @Description Get list of all roles per halaman
 // @Tags Roles
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Role
+// @Param page query int false "Halaman (mulai dari 1)"
+// @Param limit query int false "Jumlah per halaman (maks 100)"
+// @Param sort query string false "Urutan: id, name (awali - untuk menurun)" default(id)
+// @Param q query string false "Cari di nama role"
+// @Success 200 {object} RoleListResponse
+// @Failure 400 {object} map[string]string
 // @Router /roles/ [get]
 func GetRoles(c *gin.Context) {
-    var roles []models.Role
-    config.DB.Find(&roles)
-    c.JSON(http.StatusOK, roles)
+    params, err := roleListSpec.Parse(c.Request.URL.Query())
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    roles := []models.Role{}
+    paging, err := listing.Find(params.Apply(config.DB.Model(&models.Role{})), params, &roles)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil role"})
+        return
+    }
+    c.JSON(http.StatusOK, RoleListResponse{Items: roles, Paging: paging})
 }
 
 // GetRoleByID godoc
@@ -4,9 +4,11 @@ import (
 	"net/http"
 
 	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/listing"
 	"sanbercode-golang-batch-70-final-project/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // ==============================
@@ -98,13 +100,50 @@ func CreateSetting(c *gin.Context) {
 // GET ALL SETTINGS
 // ==============================
 
+// settingListSpec adalah kemampuan daftar setting (sort, filter & pencarian)
+var settingListSpec = listing.Spec{
+	Sortable: map[string]string{
+		"id":         "settings.id",
+		"user_id":    "settings.user_id",
+		"created_at": "settings.created_at",
+		"updated_at": "settings.updated_at",
+	},
+	DefaultSort: "id",
+	Filters: map[string]string{
+		"user_id":        "settings.user_id",
+		"allow_telegram": "settings.allow_telegram",
+		"allow_wa":       "settings.allow_wa",
+	},
+	Searchable: []string{"users.name", "settings.telegram_chatid", "settings.wa_number"},
+	SearchJoin: "JOIN users ON users.id = settings.user_id",
+}
+
+// SettingListResponse adalah satu halaman daftar setting
+type SettingListResponse struct {
+	Items  []models.Setting `json:"items"`
+	Paging listing.Paging   `json:"paging"`
+}
+
+// preloadSettingUser memilih kolom setting saja (query bisa join users) & memuat user-nya
+func preloadSettingUser(db *gorm.DB) *gorm.DB {
+	return db.Select("settings.*").Preload("User.Role")
+}
+
 // GetSettings godoc
 // @Summary Get all settings
-// @Description Ambil semua data setting (admin only)
+// @Description Ambil data setting per halaman (admin only)
 // @Tags Settings
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.Setting
+// @Param page query int false "Halaman (mulai dari 1)"
+// @Param limit query int false "Jumlah per halaman (maks 100)"
+// @Param sort query string false "Urutan: id, user_id, created_at, updated_at (awali - untuk menurun)" default(id)
+// @Param user_id query string false "Filter user, pisahkan dengan koma"
+// @Param allow_telegram query string false "Filter notifikasi Telegram (yes/no)"
+// @Param allow_wa query string false "Filter notifikasi WhatsApp (yes/no)"
+// @Param q query string false "Cari di nama user, chat ID Telegram & nomor WA"
+// @Success 200 {object} SettingListResponse
+// @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
 // @Router /settings/ [get]
 func GetSettings(c *gin.Context) {
@@ -114,9 +153,19 @@ func GetSettings(c *gin.Context) {
 		return
 	}
 
-	var settings []models.Setting
-	config.DB.Preload("User.Role").Find(&settings)
-	c.JSON(http.StatusOK, settings)
+	params, err := settingListSpec.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := []models.Setting{}
+	paging, err := listing.Find(params.Apply(config.DB.Model(&models.Setting{})), params, &settings, preloadSettingUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil setting"})
+		return
+	}
+	c.JSON(http.StatusOK, SettingListResponse{Items: settings, Paging: paging})
 }
 
 // ==============================
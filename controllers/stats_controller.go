@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTopRequesters = 10
+	maxTopRequesters     = 50
+)
+
+// StatusCount adalah jumlah surat per status
+type StatusCount struct {
+	Status string `json:"status"`
+	Total  int64  `json:"total"`
+}
+
+// TypeCount adalah jumlah surat per jenis surat
+type TypeCount struct {
+	TypeID   uint   `json:"type_id"`
+	TypeName string `json:"type_name"`
+	Total    int64  `json:"total"`
+}
+
+// MonthCount adalah jumlah surat per bulan pengajuan (format YYYY-MM)
+type MonthCount struct {
+	Month string `json:"month"`
+	Total int64  `json:"total"`
+}
+
+// TypeRejectionRate adalah perbandingan keputusan ditolak terhadap semua keputusan per jenis surat
+type TypeRejectionRate struct {
+	TypeID        uint    `json:"type_id"`
+	TypeName      string  `json:"type_name"`
+	Decisions     int64   `json:"decisions"`
+	Rejected      int64   `json:"rejected"`
+	RejectionRate float64 `json:"rejection_rate"` // 0 - 1
+}
+
+// RequesterCount adalah pemohon beserta jumlah suratnya
+type RequesterCount struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+	Total  int64  `json:"total"`
+}
+
+// LetterStatsResponse adalah ringkasan statistik surat untuk dashboard
+type LetterStatsResponse struct {
+	Total               int64               `json:"total"`
+	ByStatus            []StatusCount       `json:"by_status"`
+	ByType              []TypeCount         `json:"by_type"`
+	ByMonth             []MonthCount        `json:"by_month"`
+	AvgDecisionHours    *float64            `json:"avg_decision_hours"` // null kalau belum ada keputusan
+	RejectionRateByType []TypeRejectionRate `json:"rejection_rate_by_type"`
+	TopRequesters       []RequesterCount    `json:"top_requesters"`
+}
+
+// LetterStats godoc
+// @Summary Letter statistics
+// @Description Statistik surat untuk dashboard (admin & reviewer): jumlah per status, jenis & bulan, rata-rata waktu pengajuan sampai keputusan, tingkat penolakan per jenis dan pemohon terbanyak.
+// @Tags Stats
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Surat dibuat dari tanggal (YYYY-MM-DD)"
+// @Param to query string false "Surat dibuat sampai tanggal (YYYY-MM-DD)"
+// @Param top query int false "Jumlah pemohon teratas (maks 50)" default(10)
+// @Success 200 {object} LetterStatsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /stats/letters [get]
+func LetterStats(c *gin.Context) {
+	if !authorizeLetter(c, policy.ActionStats, nil) {
+		return
+	}
+
+	from, err := parseDateQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseDateQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if to != nil {
+		end := to.AddDate(0, 0, 1)
+		to = &end
+	}
+	top := defaultTopRequesters
+	if v := c.Query("top"); v != "" {
+		if top, err = strconv.Atoi(v); err != nil || top < 1 || top > maxTopRequesters {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parameter top harus angka 1 - 50"})
+			return
+		}
+	}
+
+	// letters menghasilkan query surat baru dengan rentang tanggal yang diminta
+	letters := func() *gorm.DB {
+		return letterListQuery(config.DB, currentActor(c), letterFilter{CreatedFrom: from, CreatedTo: to})
+	}
+
+	stats := LetterStatsResponse{
+		ByStatus:            []StatusCount{},
+		ByType:              []TypeCount{},
+		ByMonth:             []MonthCount{},
+		RejectionRateByType: []TypeRejectionRate{},
+		TopRequesters:       []RequesterCount{},
+	}
+
+	err = firstError(
+		letters().Count(&stats.Total).Error,
+
+		letters().Select("letters.status, COUNT(*) AS total").
+			Group("letters.status").Order("total DESC").Scan(&stats.ByStatus).Error,
+
+		letters().Select("letters.type_id, letter_types.name AS type_name, COUNT(*) AS total").
+			Joins("JOIN letter_types ON letter_types.id = letters.type_id").
+			Group("letters.type_id, letter_types.name").Order("total DESC").Scan(&stats.ByType).Error,
+
+		letters().Select("DATE_FORMAT(letters.created_at, '%Y-%m') AS month, COUNT(*) AS total").
+			Group("month").Order("month").Scan(&stats.ByMonth).Error,
+
+		letters().Select("letters.user_id, users.name, COUNT(*) AS total").
+			Joins("JOIN users ON users.id = letters.user_id").
+			Group("letters.user_id, users.name").Order("total DESC").Limit(top).Scan(&stats.TopRequesters).Error,
+
+		avgDecisionHours(letters(), &stats.AvgDecisionHours),
+
+		rejectionRateByType(letters(), &stats.RejectionRateByType),
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung statistik surat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// avgDecisionHours menghitung rata-rata jam setiap keputusan (diterima / ditolak) sejak
+// pengajuan terakhir sebelum keputusan tersebut, jadi waktu pemohon merevisi surat
+// yang ditolak tidak ikut terhitung
+func avgDecisionHours(letters *gorm.DB, dest **float64) error {
+	var result struct{ Hours *float64 }
+	err := letters.
+		Select("AVG(TIMESTAMPDIFF(SECOND, (SELECT MAX(submitted.created_at) FROM letter_status_histories AS submitted "+
+			"WHERE submitted.letter_id = decided.letter_id AND submitted.new_status = ? AND submitted.id < decided.id), "+
+			"decided.created_at)) / 3600 AS hours", workflow.StatusSubmitted).
+		Joins("JOIN letter_status_histories AS decided ON decided.letter_id = letters.id AND decided.new_status IN ?",
+			[]string{workflow.StatusAccepted, workflow.StatusRejected}).
+		Scan(&result).Error
+	*dest = result.Hours
+	return err
+}
+
+// rejectionRateByType menghitung tingkat penolakan dari semua keputusan per jenis surat
+func rejectionRateByType(letters *gorm.DB, dest *[]TypeRejectionRate) error {
+	err := letters.
+		Select("letters.type_id, letter_types.name AS type_name, COUNT(*) AS decisions, "+
+			"SUM(CASE WHEN letter_status_histories.new_status = ? THEN 1 ELSE 0 END) AS rejected", workflow.StatusRejected).
+		Joins("JOIN letter_status_histories ON letter_status_histories.letter_id = letters.id").
+		Joins("JOIN letter_types ON letter_types.id = letters.type_id").
+		Where("letter_status_histories.new_status IN ?", []string{workflow.StatusAccepted, workflow.StatusRejected}).
+		Group("letters.type_id, letter_types.name").Order("letters.type_id").
+		Scan(dest).Error
+	if err != nil {
+		return err
+	}
+	for i := range *dest {
+		r := &(*dest)[i]
+		if r.Decisions > 0 {
+			r.RejectionRate = float64(r.Rejected) / float64(r.Decisions)
+		}
+	}
+	return nil
+}
+
+// firstError mengembalikan error pertama yang tidak nil
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/notification"
+	"sanbercode-golang-batch-70-final-project/policy"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ===============================
+// Bot Telegram interaktif
+// ===============================
+
+// Data tombol review di Telegram: "<aksi>:<id surat>"
+const (
+	callbackAccept = "accept"
+	callbackReject = "reject"
+)
+
+// telegramReplyTimeout adalah batas waktu mengirim balasan bot
+const telegramReplyTimeout = 30 * time.Second
+
+// rejectPromptPattern membaca ID surat dari pesan bot yang meminta alasan penolakan.
+// ID disimpan di teks pesan supaya balasan tetap bisa diproses walau server restart.
+var rejectPromptPattern = regexp.MustCompile(`alasan penolakan surat #(\d+)`)
+
+// reviewActions adalah tombol Terima / Tolak yang ditempel pada notifikasi untuk approver
+func reviewActions(letterID uint) []notification.Action {
+	return []notification.Action{
+		{Label: "✅ Terima", Data: fmt.Sprintf("%s:%d", callbackAccept, letterID)},
+		{Label: "❌ Tolak", Data: fmt.Sprintf("%s:%d", callbackReject, letterID)},
+	}
+}
+
+// parseReviewCallback membaca aksi & ID surat dari data tombol
+func parseReviewCallback(data string) (string, uint, bool) {
+	action, rawID, ok := strings.Cut(data, ":")
+	if !ok || (action != callbackAccept && action != callbackReject) {
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(rawID, 10, 64)
+	if err != nil || id == 0 {
+		return "", 0, false
+	}
+	return action, uint(id), true
+}
+
+// StartTelegramBot menjalankan goroutine yang membaca update dari Telegram (long polling)
+// dan memproses tombol Terima / Tolak pada notifikasi surat. Tidak berjalan kalau
+// TELEGRAM_TOKEN kosong.
+func StartTelegramBot() {
+	if os.Getenv("TELEGRAM_TOKEN") == "" {
+		log.Println("TELEGRAM_TOKEN belum diatur, bot Telegram tidak dijalankan")
+		return
+	}
+
+	go func() {
+		bot, err := notification.TelegramBot()
+		for err != nil {
+			log.Println("Bot Telegram belum bisa dijalankan, dicoba lagi 1 menit lagi:", err)
+			time.Sleep(time.Minute)
+			bot, err = notification.TelegramBot()
+		}
+
+		u := tgbotapi.NewUpdate(0)
+		u.Timeout = 30
+		u.AllowedUpdates = []string{"message", "callback_query"}
+		for update := range bot.GetUpdatesChan(u) {
+			switch {
+			case update.CallbackQuery != nil:
+				handleReviewCallback(bot, update.CallbackQuery)
+			case update.Message != nil && update.Message.ReplyToMessage != nil:
+				handleRejectReason(bot, update.Message)
+			}
+		}
+	}()
+}
+
+// telegramReviewer mencari user pemilik chat Telegram lewat setting notifikasi
+// dan memastikan user tersebut boleh memutuskan surat. Hanya chat pribadi yang
+// diterima (pengirim = pemilik chat) dan notifikasi Telegram user harus aktif.
+func telegramReviewer(chat *tgbotapi.Chat, from *tgbotapi.User) (policy.Actor, error) {
+	if chat == nil || from == nil || !chat.IsPrivate() || from.ID != chat.ID {
+		return policy.Actor{}, errors.New("Keputusan surat hanya bisa dari chat pribadi dengan bot")
+	}
+
+	var setting models.Setting
+	if err := config.DB.Preload("User.Role").
+		Where("telegram_chatid = ? AND allow_telegram = ?", strconv.FormatInt(chat.ID, 10), "yes").
+		First(&setting).Error; err != nil {
+		return policy.Actor{}, errors.New("Chat ini belum terhubung ke akun mana pun atau notifikasi Telegram nonaktif")
+	}
+
+	by := policy.Actor{ID: setting.UserID, Role: setting.User.Role.Name}
+	if err := policy.Authorize(by, policy.ActionReview, nil); err != nil {
+		return by, errors.New("Chat ini bukan milik reviewer")
+	}
+	return by, nil
+}
+
+// decideLetter menerima / menolak surat dengan aturan yang sama seperti UpdateLetter:
+// rantai persetujuan, nomor surat, riwayat & notifikasi diproses dalam satu transaksi
+func decideLetter(by policy.Actor, id uint, status, reason string) (models.Letter, error) {
+	var letter models.Letter
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&letter, id).Error; err != nil {
+			return err
+		}
+		outcome, err := reviewLetter(tx, by, &letter, status, reason)
+		if err != nil {
+			return err
+		}
+
+		notice, err := noticeLetter(tx, letter.ID)
+		if err != nil {
+			return err
+		}
+		letter = notice
+		return notifyReviewOutcome(tx, notice, outcome)
+	})
+	return letter, err
+}
+
+// handleReviewCallback memproses tombol Terima / Tolak. Terima langsung diputuskan,
+// Tolak meminta alasan lewat ForceReply.
+func handleReviewCallback(bot *tgbotapi.BotAPI, q *tgbotapi.CallbackQuery) {
+	action, id, ok := parseReviewCallback(q.Data)
+	if !ok || q.Message == nil {
+		answerCallback(bot, q.ID, "Tombol tidak dikenali")
+		return
+	}
+	chatID := q.Message.Chat.ID
+
+	by, err := telegramReviewer(q.Message.Chat, q.From)
+	if err != nil {
+		answerCallback(bot, q.ID, err.Error())
+		return
+	}
+
+	if action == callbackReject {
+		answerCallback(bot, q.ID, "")
+		prompt := tgbotapi.NewMessage(chatID, fmt.Sprintf("✍️ Tulis alasan penolakan surat #%d dengan membalas pesan ini.", id))
+		prompt.ReplyToMessageID = q.Message.MessageID
+		prompt.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, InputFieldPlaceholder: "Alasan penolakan"}
+		sendBotMessage(chatID, prompt)
+		return
+	}
+
+	letter, err := decideLetter(by, id, workflow.StatusAccepted, "")
+	if err != nil {
+		_, msg := bulkItemError(err)
+		answerCallback(bot, q.ID, msg)
+		return
+	}
+	answerCallback(bot, q.ID, "Surat diterima")
+
+	// tombol dihapus dengan mengganti teks pesan beserta hasil keputusannya
+	result := fmt.Sprintf("✅ Disetujui, status surat: %s", letter.Status)
+	sendBotMessage(chatID, tgbotapi.NewEditMessageText(chatID, q.Message.MessageID, q.Message.Text+"\n\n"+result))
+}
+
+// handleRejectReason menolak surat memakai balasan user atas pesan permintaan alasan
+func handleRejectReason(bot *tgbotapi.BotAPI, msg *tgbotapi.Message) {
+	prompt := msg.ReplyToMessage
+	if prompt.From == nil || prompt.From.ID != bot.Self.ID {
+		return
+	}
+	match := rejectPromptPattern.FindStringSubmatch(prompt.Text)
+	if match == nil {
+		return
+	}
+	id, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil {
+		return
+	}
+	chatID := msg.Chat.ID
+
+	reason := strings.TrimSpace(msg.Text)
+	if reason == "" {
+		sendBotMessage(chatID, tgbotapi.NewMessage(chatID, "Alasan penolakan wajib diisi, silakan tekan tombol Tolak lagi."))
+		return
+	}
+
+	by, err := telegramReviewer(msg.Chat, msg.From)
+	if err != nil {
+		sendBotMessage(chatID, tgbotapi.NewMessage(chatID, err.Error()))
+		return
+	}
+
+	letter, err := decideLetter(by, uint(id), workflow.StatusRejected, reason)
+	if err != nil {
+		_, text := bulkItemError(err)
+		sendBotMessage(chatID, tgbotapi.NewMessage(chatID, fmt.Sprintf("Gagal menolak surat #%d: %s", id, text)))
+		return
+	}
+	reply := tgbotapi.NewMessage(chatID, fmt.Sprintf("❌ Surat #%d ditolak, status surat: %s", id, letter.Status))
+	reply.ReplyToMessageID = msg.MessageID
+	sendBotMessage(chatID, reply)
+}
+
+// answerCallback menutup loading tombol di Telegram, opsional dengan teks singkat
+func answerCallback(bot *tgbotapi.BotAPI, callbackID, text string) {
+	if _, err := bot.Request(tgbotapi.NewCallback(callbackID, text)); err != nil {
+		log.Println("Gagal menjawab tombol Telegram:", err)
+	}
+}
+
+// sendBotMessage mengirim balasan bot lewat antrean Telegram bersama
+func sendBotMessage(chatID int64, msg tgbotapi.Chattable) {
+	ctx, cancel := context.WithTimeout(context.Background(), telegramReplyTimeout)
+	defer cancel()
+	if _, err := notification.SendTelegramMessage(ctx, chatID, msg); err != nil {
+		log.Println("Gagal mengirim balasan bot Telegram:", err)
+	}
+}
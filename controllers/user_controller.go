@@ -4,10 +4,12 @@ import (
 	"net/http"
 
 	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/listing"
 	"sanbercode-golang-batch-70-final-project/models"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // ===== Struct tambahan untuk dokumentasi Swagger =====
@@ -68,18 +70,58 @@ func CreateUser(c *gin.Context) {
 	c.JSON(http.StatusCreated, user)
 }
 
+// userListSpec adalah kemampuan daftar user (sort, filter & pencarian)
+var userListSpec = listing.Spec{
+	Sortable: map[string]string{
+		"id":         "users.id",
+		"name":       "users.name",
+		"email":      "users.email",
+		"created_at": "users.created_at",
+	},
+	DefaultSort: "id",
+	Filters:     map[string]string{"role_id": "users.role_id"},
+	Searchable:  []string{"users.name", "users.email"},
+}
+
+// UserListResponse adalah satu halaman daftar user
+type UserListResponse struct {
+	Items  []models.User  `json:"items"`
+	Paging listing.Paging `json:"paging"`
+}
+
 // GetUsers godoc
 // @Summary Get all users
-// @Description Get all users (only admin can access)
+// @Description Get all users per halaman (only admin can access)
 // @Tags Users
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} models.User
+// @Param page query int false "Halaman (mulai dari 1)"
+// @Param limit query int false "Jumlah per halaman (maks 100)"
+// @Param sort query string false "Urutan: id, name, email, created_at (awali - untuk menurun)" default(id)
+// @Param role_id query string false "Filter role, pisahkan dengan koma"
+// @Param q query string false "Cari di nama & email"
+// @Success 200 {object} UserListResponse
+// @Failure 400 {object} map[string]string
 // @Router /users/ [get]
 func GetUsers(c *gin.Context) {
-	var users []models.User
-	config.DB.Preload("Role").Find(&users)
-	c.JSON(http.StatusOK, users)
+	params, err := userListSpec.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users := []models.User{}
+	paging, err := listing.Find(params.Apply(config.DB.Model(&models.User{})), params, &users, preloadRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil user"})
+		return
+	}
+	c.JSON(http.StatusOK, UserListResponse{Items: users, Paging: paging})
+}
+
+// preloadRole memuat role user di daftar user
+func preloadRole(db *gorm.DB) *gorm.DB {
+	return db.Preload("Role")
 }
 
 // GetUserByID godoc
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"sanbercode-golang-batch-70-final-project/config"
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"github.com/gin-gonic/gin"
+)
+
+// verificationURL menyusun URL publik untuk QR code surat dari APP_URL (misal domain Railway).
+// Header Host dari request tidak dipakai karena bisa dipalsukan pengirim request;
+// tanpa APP_URL QR code mengarah ke server lokal.
+func verificationURL(token string) string {
+	base := strings.TrimRight(os.Getenv("APP_URL"), "/")
+	if base == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		base = "http://localhost:" + port
+	}
+	return fmt.Sprintf("%s/api/verify/%s", base, token)
+}
+
+// issuedApproval mengembalikan riwayat persetujuan surat yang masih berlaku, yaitu surat
+// accepted (atau diarsipkan setelah accepted) yang tidak pernah dipindah ke cancelled.
+// Pencabutan dicek dari riwayat status, bukan CancelReason, supaya surat yang dicabut
+// lewat jalur mana pun (lalu diarsipkan) tetap dianggap tidak berlaku.
+func issuedApproval(letter models.Letter) (models.LetterStatusHistory, bool) {
+	var approval models.LetterStatusHistory
+	if letter.Status != workflow.StatusAccepted && letter.Status != workflow.StatusArchived {
+		return approval, false
+	}
+	if err := config.DB.Where("letter_id = ? AND new_status = ?", letter.ID, workflow.StatusAccepted).
+		Order("created_at DESC, id DESC").First(&approval).Error; err != nil {
+		return approval, false
+	}
+
+	var cancelled int64
+	if err := config.DB.Model(&models.LetterStatusHistory{}).
+		Where("letter_id = ? AND new_status = ?", letter.ID, workflow.StatusCancelled).
+		Count(&cancelled).Error; err != nil || cancelled > 0 {
+		return approval, false
+	}
+	return approval, true
+}
+
+// VerifyLetter godoc
+// @Summary Verify an issued letter
+// @Description Endpoint publik (tanpa login) untuk mengecek keaslian surat dari QR code
+// @Tags Verification
+// @Produce json
+// @Param token path string true "Verification token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /verify/{token} [get]
+func VerifyLetter(c *gin.Context) {
+	var letter models.Letter
+	err := config.DB.Preload("User").Preload("LetterType").
+		Where("verification_token = ?", c.Param("token")).First(&letter).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"valid": false, "error": "Surat tidak ditemukan atau tidak valid"})
+		return
+	}
+
+	_, valid := issuedApproval(letter)
+	c.JSON(http.StatusOK, gin.H{
+		"valid":       valid,
+		"number":      letter.Number, // null untuk surat lama yang PDF-nya dibuat sebelum ada penomoran
+		"letter_type": letter.LetterType.Name,
+		"holder_name": letter.User.Name,
+		"issued_at":   letter.IssuedAt,
+		"status":      letter.Status,
+	})
+}
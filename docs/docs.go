@@ -22,7 +22,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all letter types (admin only)",
+                "description": "Get all letter types per halaman (admin only)",
                 "produces": [
                     "application/json"
                 ],
@@ -30,13 +30,58 @@ const docTemplate = `{
                     "Letter Types"
                 ],
                 "summary": "Get all letter types",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (mulai dari 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Urutan: id, name (awali - untuk menurun)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter strategi pembagian reviewer",
+                        "name": "assignment_strategy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter reset nomor surat",
+                        "name": "number_reset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di nama, deskripsi \u0026 kode nomor",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.LetterType"
+                            "$ref": "#/definitions/controllers.LetterTypeListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
@@ -48,7 +93,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new letter type (admin only)",
+                "description": "Create a new letter type (admin only). number_code wajib diisi \u0026 unik antar jenis surat.",
                 "consumes": [
                     "application/json"
                 ],
@@ -79,6 +124,13 @@ const docTemplate = `{
                     },
                     "400": {
                         "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -137,7 +189,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update letter type (admin only)",
+                "description": "Update letter type (admin only). Field yang tidak dikirim tetap memakai nilai lama; approval_steps yang dikirim mengganti seluruh tahap.",
                 "consumes": [
                     "application/json"
                 ],
@@ -173,6 +225,13 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.LetterType"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -181,6 +240,15 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             },
@@ -229,40 +297,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/letters/": {
+        "/letter_types/{id}/reviewers": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all letters (admin \u0026 reviewer only)",
+                "description": "Ambil strategi pembagian \u0026 kandidat reviewer jenis surat beserta beban kerjanya (admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Letters"
+                    "Letter Types"
+                ],
+                "summary": "Get reviewer pool of a letter type",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Letter Type ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all letters",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Letter"
+                            "$ref": "#/definitions/controllers.LetterTypeReviewersResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Buat pengajuan surat baru (user \u0026 admin bisa)",
+                "description": "Ganti seluruh anggota pool reviewer jenis surat (admin only). Kosongkan user_ids agar semua reviewer jadi kandidat.",
                 "consumes": [
                     "application/json"
                 ],
@@ -270,38 +353,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Letters"
+                    "Letter Types"
                 ],
-                "summary": "Create a new letter",
+                "summary": "Replace reviewer pool of a letter type",
                 "parameters": [
                     {
-                        "description": "Letter create payload",
+                        "type": "integer",
+                        "description": "Letter Type ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Anggota pool reviewer",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.LetterCreateInput"
+                            "$ref": "#/definitions/controllers.LetterTypeReviewersInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Letter"
+                            "$ref": "#/definitions/controllers.LetterTypeReviewersResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -312,28 +400,97 @@ const docTemplate = `{
                 }
             }
         },
-        "/roles/": {
+        "/letters/": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get list of all roles",
+                "description": "Ambil daftar surat per halaman. User hanya melihat surat miliknya, admin \u0026 reviewer melihat semua.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Roles"
+                    "Letters"
+                ],
+                "summary": "Get all letters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (mulai dari 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Urutan, misal -created_at atau status,-id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status, pisahkan dengan koma",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter jenis surat",
+                        "name": "type_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter pemohon",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal dibuat dari (YYYY-MM-DD)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal dibuat sampai (YYYY-MM-DD)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Hanya surat yang melewati tenggat review",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di nama pemohon, perihal, isi \u0026 nomor surat",
+                        "name": "q",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Role"
+                            "$ref": "#/definitions/controllers.LetterListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     }
@@ -345,37 +502,45 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new role (only admin can access)",
+                "description": "Buat pengajuan surat baru (user \u0026 admin bisa).\nKirim sebagai multipart/form-data untuk sekaligus upload lampiran di field \"attachments\"\n(field \"fields\" berisi string JSON).",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Roles"
+                    "Letters"
                 ],
-                "summary": "Create new role",
+                "summary": "Create a new letter",
                 "parameters": [
                     {
-                        "description": "Role input",
+                        "description": "Letter create payload",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.RoleInput"
+                            "$ref": "#/definitions/controllers.LetterCreateInput"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Role"
+                            "$ref": "#/definitions/models.Letter"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -386,39 +551,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/roles/{id}": {
-            "get": {
+        "/letters/bulk-decision": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get detail of a specific role",
+                "description": "Terima atau tolak banyak surat sekaligus (reviewer \u0026 admin). Setiap surat diproses di savepoint sendiri\ndalam satu transaksi, sehingga surat yang gagal tidak membatalkan surat lain. Setiap pemohon \u0026 approver\nberikutnya hanya menerima satu notifikasi ringkasan.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Roles"
+                    "Letters"
                 ],
-                "summary": "Get role by ID",
+                "summary": "Bulk accept / reject letters",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Daftar surat \u0026 keputusan",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LetterBulkDecisionInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Role"
+                            "$ref": "#/definitions/controllers.LetterBulkDecisionResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -427,51 +606,91 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/letters/export": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update role name (only admin can access)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Unduh daftar surat untuk laporan dengan filter yang sama seperti daftar surat. Data di-stream per batch sehingga aman untuk data besar.",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "Roles"
+                    "Letters"
                 ],
-                "summary": "Update role by ID",
+                "summary": "Export letters to CSV / XLSX",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Format file: csv atau xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Urutan, misal -created_at atau status,-id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status, pisahkan dengan koma",
+                        "name": "status",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter jenis surat",
+                        "name": "type_id",
+                        "in": "query"
                     },
                     {
-                        "description": "Role update input",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.RoleInput"
-                        }
+                        "type": "integer",
+                        "description": "Filter pemohon",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal dibuat dari (YYYY-MM-DD)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal dibuat sampai (YYYY-MM-DD)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Hanya surat yang melewati tenggat review",
+                        "name": "overdue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di nama pemohon, perihal, isi \u0026 nomor surat",
+                        "name": "q",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Role"
+                            "type": "file"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -480,33 +699,80 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/letters/{id}/assignee": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete role (only admin can access)",
+                "description": "Tugaskan ulang surat yang menunggu keputusan ke reviewer lain (admin only). Hanya reviewer baru yang diberi tahu.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Roles"
+                    "Letters"
                 ],
-                "summary": "Delete role by ID",
+                "summary": "Reassign a letter to a reviewer",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role ID",
+                        "description": "Letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    {
+                        "description": "Reviewer tujuan",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LetterAssignInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Letter"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -517,28 +783,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/settings/": {
+        "/letters/{id}/attachments": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Ambil semua data setting (admin only)",
+                "description": "Daftar lampiran surat. User hanya bisa melihat lampiran surat miliknya.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Attachments"
+                ],
+                "summary": "List letter attachments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all settings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Setting"
+                                "$ref": "#/definitions/models.Attachment"
                             }
                         }
                     },
@@ -550,6 +825,15 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             },
@@ -559,33 +843,41 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Buat pengaturan notifikasi baru (admin only)",
+                "description": "Tambah lampiran (PDF/JPG/PNG) ke surat yang belum diputuskan. Pemilik surat \u0026 admin.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Attachments"
                 ],
-                "summary": "Create a new setting",
+                "summary": "Upload letter attachments",
                 "parameters": [
                     {
-                        "description": "Setting create payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.SettingCreateInput"
-                        }
+                        "type": "integer",
+                        "description": "Letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File lampiran (boleh lebih dari satu)",
+                        "name": "attachments",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Setting"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Attachment"
+                            }
                         }
                     },
                     "400": {
@@ -605,39 +897,64 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/settings/{id}": {
+        "/letters/{id}/attachments/{attachment_id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Ambil detail setting berdasarkan ID",
+                "description": "Unduh file lampiran surat. User hanya bisa mengunduh lampiran surat miliknya.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Settings"
+                    "Attachments"
                 ],
-                "summary": "Get setting by ID",
+                "summary": "Download letter attachment",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Setting ID",
+                        "description": "Letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Attachment ID",
+                        "name": "attachment_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Setting"
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
@@ -650,14 +967,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/letters/{id}/cancel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Ubah data setting (admin only)",
+                "description": "Pemilik surat menarik pengajuan yang belum diputuskan beserta alasannya. Reviewer akan diberi tahu.\nAdmin juga bisa mencabut surat yang sudah diterima; pemilik surat akan diberi tahu dan verifikasi surat menjadi tidak berlaku.",
                 "consumes": [
                     "application/json"
                 ],
@@ -665,24 +984,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Letters"
                 ],
-                "summary": "Update setting",
+                "summary": "Cancel (withdraw) a letter",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Setting ID",
+                        "description": "Letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Setting update payload",
+                        "description": "Alasan pembatalan",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.SettingUpdateInput"
+                            "$ref": "#/definitions/controllers.LetterCancelInput"
                         }
                     }
                 ],
@@ -690,7 +1009,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Setting"
+                            "$ref": "#/definitions/models.Letter"
                         }
                     },
                     "400": {
@@ -719,27 +1038,38 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/letters/{id}/comments": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Hapus setting (admin only)",
+                "description": "Ambil komentar surat urut dari yang terlama. Komentar internal hanya terlihat oleh reviewer \u0026 admin.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Settings"
+                    "Letters"
                 ],
-                "summary": "Delete setting",
+                "summary": "Get letter comments",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Setting ID",
+                        "description": "Letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -749,9 +1079,9 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LetterComment"
                             }
                         }
                     },
@@ -774,34 +1104,6 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all users (only admin can access)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Get all users",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
-                        }
-                    }
-                }
             },
             "post": {
                 "security": [
@@ -809,7 +1111,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new user (only admin can access)",
+                "description": "Tulis komentar / pertanyaan pada surat. Pemohon diberi tahu saat reviewer berkomentar, dan sebaliknya.",
                 "consumes": [
                     "application/json"
                 ],
@@ -817,17 +1119,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Letters"
                 ],
-                "summary": "Create a new user",
+                "summary": "Comment on a letter",
                 "parameters": [
                     {
-                        "description": "User input",
+                        "type": "integer",
+                        "description": "Letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Isi komentar",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.UserInput"
+                            "$ref": "#/definitions/controllers.LetterCommentInput"
                         }
                     }
                 ],
@@ -835,7 +1144,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.LetterComment"
                         }
                     },
                     "400": {
@@ -846,32 +1155,57 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/users/login": {
-            "post": {
-                "description": "Login user menggunakan email dan password, menghasilkan JWT token",
-                "consumes": [
-                    "application/json"
+        "/letters/{id}/comments/{comment_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Hapus komentar (penulis komentar atau admin)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Letters"
                 ],
-                "summary": "Login user",
+                "summary": "Delete a letter comment",
                 "parameters": [
                     {
-                        "description": "Data login user",
-                        "name": "login",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.LoginInput"
-                        }
+                        "type": "integer",
+                        "description": "Letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Comment ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -879,11 +1213,13 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -891,8 +1227,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -903,40 +1239,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/register": {
-            "post": {
-                "description": "Membuat akun user baru dengan role default \"user\"",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
+        "/letters/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ambil riwayat perubahan status surat (timeline). User hanya bisa melihat surat miliknya.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Letters"
                 ],
-                "summary": "Register user baru",
+                "summary": "Get letter status history",
                 "parameters": [
                     {
-                        "description": "Data user baru",
-                        "name": "register",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RegisterInput"
-                        }
+                        "type": "integer",
+                        "description": "Letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LetterStatusHistory"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -947,25 +1294,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/letters/{id}/pdf": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get user detail by ID (only admin can access)",
+                "description": "Generate dokumen PDF dari template jenis surat untuk surat yang sudah disetujui (accepted) dan tidak pernah dicabut",
                 "produces": [
-                    "application/json"
+                    "application/pdf"
                 ],
                 "tags": [
-                    "Users"
+                    "Letters"
                 ],
-                "summary": "Get user by ID",
+                "summary": "Download letter PDF",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -975,7 +1322,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
@@ -986,16 +1342,27 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/letters/{id}/resubmit": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update user data (only admin can access)",
+                "description": "Pemilik surat merevisi isi surat yang ditolak lalu mengirimnya kembali ke reviewer.\nIsi \u0026 alasan penolakan versi sebelumnya disimpan sebagai revisi.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1003,24 +1370,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Letters"
                 ],
-                "summary": "Update user by ID",
+                "summary": "Resubmit a rejected letter",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User update input",
+                        "description": "Isi surat yang direvisi",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.UserUpdateInput"
+                            "$ref": "#/definitions/controllers.LetterResubmitInput"
                         }
                     }
                 ],
@@ -1028,7 +1395,23 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/models.Letter"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
@@ -1039,27 +1422,38 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/letters/{id}/revisions": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete user (only admin can access)",
+                "description": "Ambil isi surat versi-versi sebelumnya beserta alasan penolakannya",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Letters"
                 ],
-                "summary": "Delete user by ID",
+                "summary": "Get letter revisions",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Letter ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1068,6 +1462,24 @@ const docTemplate = `{
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LetterRevision"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -1077,82 +1489,1840 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.LetterCreateInput": {
-            "type": "object",
-            "required": [
-                "type_id"
-            ],
-            "properties": {
-                "type_id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "user_id": {
-                    "type": "integer",
-                    "example": 5
-                }
-            }
-        },
-        "controllers.LetterTypeInput": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string",
-                    "example": "Deskripsi surat Test"
-                },
-                "name": {
-                    "type": "string",
-                    "example": "Surat Test"
-                }
-            }
         },
-        "controllers.LoginInput": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": "admin@mail.com"
-                },
-                "password": {
-                    "type": "string",
-                    "example": "admin123"
-                }
-            }
-        },
-        "controllers.RoleInput": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string",
-                    "example": "test"
-                }
-            }
-        },
-        "controllers.SettingCreateInput": {
-            "type": "object",
-            "properties": {
-                "allow_telegram": {
-                    "type": "string",
-                    "example": "yes"
-                },
-                "allow_wa": {
-                    "type": "string",
-                    "example": "yes"
-                },
-                "telegram_chatid": {
-                    "type": "string",
-                    "example": "123456789"
-                },
-                "user_id": {
-                    "type": "integer",
-                    "example": 5
-                },
-                "wa_number": {
-                    "type": "string",
-                    "example": "6281234567890"
+        "/letters/{id}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Kirim surat berstatus draft ke reviewer (pemilik surat \u0026 admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Letters"
+                ],
+                "summary": "Submit a draft letter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Letter"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ambil log setiap percobaan pengiriman notifikasi per halaman (admin only): channel, tujuan, surat, isi pesan, status, ID pesan dari provider \u0026 pesan error.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Get notification delivery log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (mulai dari 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-id",
+                        "description": "Urutan: id, started_at, attempt (awali - untuk menurun)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status (sent/failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter channel (telegram/whatsapp), pisahkan dengan koma",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter surat, pisahkan dengan koma",
+                        "name": "letter_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter user penerima, pisahkan dengan koma",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter notifikasi outbox, pisahkan dengan koma",
+                        "name": "outbox_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di tujuan, isi pesan, ID pesan provider \u0026 pesan error",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.DeliveryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/outbox": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ambil antrean notifikasi per halaman (admin only). Pakai status=dead untuk melihat notifikasi yang gagal sampai batas percobaan.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Get notification outbox",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (mulai dari 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-id",
+                        "description": "Urutan: id, created_at, next_attempt_at, attempts (awali - untuk menurun)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status (pending/processing/sent/dead), pisahkan dengan koma",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter channel (telegram/whatsapp), pisahkan dengan koma",
+                        "name": "channel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter surat, pisahkan dengan koma",
+                        "name": "letter_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter user penerima, pisahkan dengan koma",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di tujuan, isi pesan \u0026 pesan error",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OutboxListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/outbox/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Kembalikan semua notifikasi berstatus dead ke antrean (admin only), opsional hanya untuk satu channel",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Re-drive all dead notifications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hanya channel ini (telegram/whatsapp)",
+                        "name": "channel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.OutboxRetryResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/outbox/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Kembalikan notifikasi berstatus dead ke antrean dengan jatah percobaan baru (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Re-drive a dead notification",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Outbox ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationOutbox"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get list of all roles per halaman",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get all roles",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (mulai dari 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Urutan: id, name (awali - untuk menurun)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di nama role",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RoleListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new role (only admin can access)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Create new role",
+                "parameters": [
+                    {
+                        "description": "Role input",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RoleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get detail of a specific role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Get role by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update role name (only admin can access)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Update role by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role update input",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.RoleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete role (only admin can access)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Roles"
+                ],
+                "summary": "Delete role by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ambil data setting per halaman (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get all settings",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (mulai dari 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Urutan: id, user_id, created_at, updated_at (awali - untuk menurun)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter user, pisahkan dengan koma",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter notifikasi Telegram (yes/no)",
+                        "name": "allow_telegram",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter notifikasi WhatsApp (yes/no)",
+                        "name": "allow_wa",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di nama user, chat ID Telegram \u0026 nomor WA",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SettingListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Buat pengaturan notifikasi baru (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Create a new setting",
+                "parameters": [
+                    {
+                        "description": "Setting create payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SettingCreateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ambil detail setting berdasarkan ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Get setting by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Setting ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ubah data setting (admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Update setting",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Setting ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Setting update payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.SettingUpdateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Setting"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Hapus setting (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Settings"
+                ],
+                "summary": "Delete setting",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Setting ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/letters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Statistik surat untuk dashboard (admin \u0026 reviewer): jumlah per status, jenis \u0026 bulan, rata-rata waktu pengajuan sampai keputusan, tingkat penolakan per jenis dan pemohon terbanyak.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stats"
+                ],
+                "summary": "Letter statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Surat dibuat dari tanggal (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Surat dibuat sampai tanggal (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Jumlah pemohon teratas (maks 50)",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LetterStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all users per halaman (only admin can access)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get all users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Halaman (mulai dari 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "Urutan: id, name, email, created_at (awali - untuk menurun)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter role, pisahkan dengan koma",
+                        "name": "role_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cari di nama \u0026 email",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UserListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new user (only admin can access)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User input",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UserInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "Login user menggunakan email dan password, menghasilkan JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Data login user",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.LoginInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "Membuat akun user baru dengan role default \"user\"",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register user baru",
+                "parameters": [
+                    {
+                        "description": "Data user baru",
+                        "name": "register",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RegisterInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get user detail by ID (only admin can access)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update user data (only admin can access)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User update input",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UserUpdateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete user (only admin can access)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/verify/{token}": {
+            "get": {
+                "description": "Endpoint publik (tanpa login) untuk mengecek keaslian surat dari QR code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Verification"
+                ],
+                "summary": "Verify an issued letter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.ApprovalStepInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Reviewer bagian"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "reviewer"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "controllers.BulkDecisionResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "kode HTTP yang setara untuk surat ini",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "letter_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "status surat setelah diproses",
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.DeliveryListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotificationDelivery"
+                    }
+                },
+                "paging": {
+                    "$ref": "#/definitions/listing.Paging"
+                }
+            }
+        },
+        "controllers.LetterAssignInput": {
+            "type": "object",
+            "required": [
+                "reviewer_id"
+            ],
+            "properties": {
+                "reviewer_id": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "controllers.LetterBulkDecisionInput": {
+            "type": "object",
+            "required": [
+                "letter_ids",
+                "status"
+            ],
+            "properties": {
+                "letter_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3
+                    ]
+                },
+                "reject_reason": {
+                    "type": "string",
+                    "example": "Berkas tidak lengkap"
+                },
+                "status": {
+                    "description": "accepted / rejected",
+                    "type": "string",
+                    "example": "accepted"
+                }
+            }
+        },
+        "controllers.LetterBulkDecisionResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.BulkDecisionResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.LetterCancelInput": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Salah memilih jenis surat"
+                }
+            }
+        },
+        "controllers.LetterCommentInput": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Mohon lampirkan KTM terbaru"
+                },
+                "internal": {
+                    "description": "hanya reviewer \u0026 admin",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "controllers.LetterCreateInput": {
+            "type": "object",
+            "required": [
+                "purpose",
+                "subject",
+                "type_id"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Mohon dibuatkan surat keterangan aktif kuliah semester ganjil."
+                },
+                "draft": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "fields": {
+                    "type": "object"
+                },
+                "purpose": {
+                    "type": "string",
+                    "example": "Syarat pengajuan beasiswa"
+                },
+                "subject": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Surat keterangan aktif kuliah"
+                },
+                "type_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "controllers.LetterListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Letter"
+                    }
+                },
+                "paging": {
+                    "$ref": "#/definitions/listing.Paging"
+                }
+            }
+        },
+        "controllers.LetterResubmitInput": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "Sudah dilengkapi sesuai catatan reviewer"
+                },
+                "fields": {
+                    "type": "object"
+                },
+                "purpose": {
+                    "type": "string",
+                    "example": "Syarat pengajuan beasiswa"
+                },
+                "subject": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "Surat keterangan aktif kuliah (revisi)"
+                }
+            }
+        },
+        "controllers.LetterStatsResponse": {
+            "type": "object",
+            "properties": {
+                "avg_decision_hours": {
+                    "description": "null kalau belum ada keputusan",
+                    "type": "number"
+                },
+                "by_month": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.MonthCount"
+                    }
+                },
+                "by_status": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.StatusCount"
+                    }
+                },
+                "by_type": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TypeCount"
+                    }
+                },
+                "rejection_rate_by_type": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.TypeRejectionRate"
+                    }
+                },
+                "top_requesters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.RequesterCount"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.LetterTypeInput": {
+            "type": "object",
+            "properties": {
+                "approval_steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ApprovalStepInput"
+                    }
+                },
+                "assignment_strategy": {
+                    "type": "string",
+                    "example": "round_robin"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Deskripsi surat Test"
+                },
+                "escalation_hours": {
+                    "type": "integer",
+                    "example": 72
+                },
+                "form_schema": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FormField"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Surat Test"
+                },
+                "number_code": {
+                    "type": "string",
+                    "example": "SK"
+                },
+                "number_format": {
+                    "type": "string",
+                    "example": "{seq}/{code}/{month_roman}/{year}"
+                },
+                "number_padding": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "number_reset": {
+                    "type": "string",
+                    "example": "yearly"
+                },
+                "review_sla_hours": {
+                    "type": "integer",
+                    "example": 48
+                },
+                "template": {
+                    "type": "string",
+                    "example": "Menerangkan bahwa {{.UserName}} ({{.Fields.nim}}) adalah mahasiswa aktif."
+                }
+            }
+        },
+        "controllers.LetterTypeListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LetterType"
+                    }
+                },
+                "paging": {
+                    "$ref": "#/definitions/listing.Paging"
+                }
+            }
+        },
+        "controllers.LetterTypeReviewersInput": {
+            "type": "object",
+            "properties": {
+                "user_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        2,
+                        3
+                    ]
+                }
+            }
+        },
+        "controllers.LetterTypeReviewersResponse": {
+            "type": "object",
+            "properties": {
+                "assignment_strategy": {
+                    "type": "string"
+                },
+                "reviewers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/controllers.ReviewerLoad"
+                    }
+                },
+                "type_id": {
+                    "type": "integer"
+                },
+                "uses_pool": {
+                    "description": "false = semua reviewer jadi kandidat",
+                    "type": "boolean"
+                }
+            }
+        },
+        "controllers.LoginInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "admin@mail.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "admin123"
+                }
+            }
+        },
+        "controllers.MonthCount": {
+            "type": "object",
+            "properties": {
+                "month": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.OutboxListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotificationOutbox"
+                    }
+                },
+                "paging": {
+                    "$ref": "#/definitions/listing.Paging"
+                }
+            }
+        },
+        "controllers.OutboxRetryResponse": {
+            "type": "object",
+            "properties": {
+                "requeued": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.RequesterCount": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ReviewerLoad": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.RoleInput": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "test"
+                }
+            }
+        },
+        "controllers.RoleListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Role"
+                    }
+                },
+                "paging": {
+                    "$ref": "#/definitions/listing.Paging"
+                }
+            }
+        },
+        "controllers.SettingCreateInput": {
+            "type": "object",
+            "properties": {
+                "allow_telegram": {
+                    "type": "string",
+                    "example": "yes"
+                },
+                "allow_wa": {
+                    "type": "string",
+                    "example": "yes"
+                },
+                "telegram_chatid": {
+                    "type": "string",
+                    "example": "123456789"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "wa_number": {
+                    "type": "string",
+                    "example": "6281234567890"
+                }
+            }
+        },
+        "controllers.SettingListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Setting"
+                    }
+                },
+                "paging": {
+                    "$ref": "#/definitions/listing.Paging"
                 }
             }
         },
@@ -1177,6 +3347,52 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.StatusCount": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.TypeCount": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "integer"
+                },
+                "type_id": {
+                    "type": "integer"
+                },
+                "type_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.TypeRejectionRate": {
+            "type": "object",
+            "properties": {
+                "decisions": {
+                    "type": "integer"
+                },
+                "rejected": {
+                    "type": "integer"
+                },
+                "rejection_rate": {
+                    "description": "0 - 1",
+                    "type": "number"
+                },
+                "type_id": {
+                    "type": "integer"
+                },
+                "type_name": {
+                    "type": "string"
+                }
+            }
+        },
         "controllers.UserInput": {
             "type": "object",
             "properties": {
@@ -1192,51 +3408,243 @@ const docTemplate = `{
                     "type": "string",
                     "example": "password123"
                 },
-                "role_id": {
-                    "type": "integer",
-                    "example": 3
+                "role_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "controllers.UserListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.User"
+                    }
+                },
+                "paging": {
+                    "$ref": "#/definitions/listing.Paging"
+                }
+            }
+        },
+        "controllers.UserUpdateInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "baru@mail.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "User Baru"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "newpassword123"
+                },
+                "role_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "listing.Paging": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_page": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ApprovalStep": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "step_order": {
+                    "type": "integer"
+                },
+                "type_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Attachment": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "file_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "letter_id": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "uploaded_by": {
+                    "type": "integer"
                 }
             }
         },
-        "controllers.UserUpdateInput": {
+        "models.FormField": {
             "type": "object",
             "properties": {
-                "email": {
+                "label": {
                     "type": "string",
-                    "example": "baru@mail.com"
+                    "example": "Nomor Induk Mahasiswa"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
                 },
                 "name": {
                     "type": "string",
-                    "example": "User Baru"
+                    "example": "nim"
                 },
-                "password": {
+                "options": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pattern": {
                     "type": "string",
-                    "example": "newpassword123"
+                    "example": "^[0-9]{10}$"
                 },
-                "role_id": {
-                    "type": "integer",
-                    "example": 3
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "type": {
+                    "description": "text, date, number, select",
+                    "type": "string",
+                    "example": "text"
                 }
             }
         },
+        "models.JSONMap": {
+            "type": "object",
+            "additionalProperties": true
+        },
         "models.Letter": {
             "type": "object",
             "properties": {
+                "approvals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LetterApproval"
+                    }
+                },
+                "assigned_reviewer": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "assigned_reviewer_id": {
+                    "type": "integer"
+                },
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attachment"
+                    }
+                },
+                "body": {
+                    "type": "string"
+                },
+                "cancel_reason": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "current_step": {
+                    "type": "integer"
+                },
+                "due_at": {
+                    "type": "string"
+                },
+                "escalate_at": {
+                    "type": "string"
+                },
+                "escalated_at": {
+                    "type": "string"
+                },
+                "fields": {
+                    "$ref": "#/definitions/models.JSONMap"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "issued_at": {
+                    "type": "string"
+                },
                 "letterType": {
                     "$ref": "#/definitions/models.LetterType"
                 },
+                "notifications": {
+                    "description": "hanya diisi di detail surat",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotificationOutbox"
+                    }
+                },
+                "number": {
+                    "type": "string"
+                },
+                "overdue": {
+                    "type": "boolean"
+                },
+                "purpose": {
+                    "type": "string"
+                },
                 "reject_reason": {
                     "type": "string"
                 },
+                "reminder_sent_at": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
                 "status": {
                     "type": "string"
                 },
+                "subject": {
+                    "type": "string"
+                },
                 "type_id": {
                     "type": "integer"
                 },
@@ -1248,20 +3656,294 @@ const docTemplate = `{
                 },
                 "user_id": {
                     "type": "integer"
+                },
+                "verification_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LetterApproval": {
+            "type": "object",
+            "properties": {
+                "approver_id": {
+                    "type": "integer"
+                },
+                "approver_role": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "letter_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "step_name": {
+                    "type": "string"
+                },
+                "step_order": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LetterComment": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "internal": {
+                    "type": "boolean"
+                },
+                "letter_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LetterRevision": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "fields": {
+                    "$ref": "#/definitions/models.JSONMap"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "letter_id": {
+                    "type": "integer"
+                },
+                "purpose": {
+                    "type": "string"
+                },
+                "reject_reason": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "type_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LetterStatusHistory": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "actor_role": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "letter_id": {
+                    "type": "integer"
+                },
+                "new_status": {
+                    "type": "string"
+                },
+                "old_status": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
                 }
             }
         },
         "models.LetterType": {
             "type": "object",
             "properties": {
+                "approval_steps": {
+                    "description": "Rantai persetujuan berurutan (kosong = cukup satu keputusan reviewer)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ApprovalStep"
+                    }
+                },
+                "assignment_strategy": {
+                    "description": "Pembagian surat ke reviewer: round_robin, least_loaded atau manual",
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
+                "escalation_hours": {
+                    "type": "integer"
+                },
+                "form_schema": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FormField"
+                    }
+                },
                 "id": {
                     "type": "integer"
                 },
                 "name": {
                     "type": "string"
+                },
+                "number_code": {
+                    "description": "Penomoran surat resmi, contoh 001/SK/X/2026 (kode unik per jenis surat)",
+                    "type": "string"
+                },
+                "number_format": {
+                    "type": "string"
+                },
+                "number_padding": {
+                    "type": "integer"
+                },
+                "number_reset": {
+                    "type": "string"
+                },
+                "review_sla_hours": {
+                    "description": "Tenggat review dalam jam kerja (0 = tanpa SLA) \u0026 batas eskalasi ke admin sejak surat dikirim",
+                    "type": "integer"
+                },
+                "template": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NotificationDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "letter_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "outbox_id": {
+                    "type": "integer"
+                },
+                "provider_message_id": {
+                    "type": "string"
+                },
+                "recipient": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "sent / failed",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.NotificationOutbox": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "type": "string"
+                },
+                "attempts": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotificationDelivery"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "letter_id": {
+                    "description": "null untuk pesan ringkasan beberapa surat (lihat NotificationOutboxLetter)",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "recipient": {
+                    "type": "string"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "pending / processing / sent / dead",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -0,0 +1,90 @@
+package document
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// WritePDF merender template surat lalu menulis hasilnya sebagai PDF ke w
+func WritePDF(w io.Writer, text string, data Data) error {
+	// tanggal dipakai di isi surat & blok tanda tangan
+	data = data.withDate()
+	content, err := Render(text, data)
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(25, 20, 25)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.SetTitle(data.TypeName, true)
+	pdf.SetCreator("Surat Notifikasi API", true)
+	pdf.AddPage()
+
+	// font bawaan fpdf memakai cp1252, jadi teks UTF-8 diterjemahkan dulu
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// Kop surat
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, tr(strings.ToUpper(data.TypeName)), "", 1, "C", false, 0, "")
+	if data.Number != "" {
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 6, tr("Nomor: "+data.Number), "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	// Isi surat
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.MultiCell(0, 6, tr(content), "", "J", false)
+	pdf.Ln(12)
+
+	// QR code verifikasi di sisi kiri blok tanda tangan
+	if data.VerifyURL != "" {
+		if err := drawVerificationQR(pdf, tr, data.VerifyURL); err != nil {
+			return err
+		}
+	}
+
+	// Blok tanda tangan
+	pdf.SetX(115)
+	pdf.MultiCell(0, 6, tr(data.Date), "", "L", false)
+	pdf.SetX(115)
+	pdf.MultiCell(0, 6, tr("Disetujui secara elektronik oleh"), "", "L", false)
+	pdf.Ln(4)
+	pdf.SetX(115)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.MultiCell(0, 6, tr(data.SignerName), "", "L", false)
+	if data.SignerRole != "" {
+		pdf.SetX(115)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(data.SignerRole), "", "L", false)
+	}
+
+	return pdf.Output(w)
+}
+
+// drawVerificationQR menempelkan QR code berisi URL verifikasi surat
+func drawVerificationQR(pdf *fpdf.Fpdf, tr func(string) string, url string) error {
+	png, err := qrcode.Encode(url, qrcode.Medium, 256)
+	if err != nil {
+		return err
+	}
+
+	y := pdf.GetY()
+	opt := fpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("verification-qr", opt, bytes.NewReader(png))
+	pdf.ImageOptions("verification-qr", 25, y, 32, 32, false, opt, 0, "")
+
+	pdf.SetXY(25, y+33)
+	pdf.SetFont("Helvetica", "", 7)
+	pdf.CellFormat(60, 4, tr("Scan untuk verifikasi keaslian surat"), "", 0, "L", false, 0, "")
+
+	// kembalikan posisi supaya blok tanda tangan sejajar dengan QR
+	pdf.SetXY(115, y)
+	pdf.SetFont("Helvetica", "", 11)
+	return nil
+}
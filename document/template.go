@@ -0,0 +1,80 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate dipakai kalau jenis surat belum punya template sendiri
+const DefaultTemplate = `Yang bertanda tangan di bawah ini menerangkan bahwa:
+
+Nama   : {{.UserName}}
+Email  : {{.UserEmail}}
+
+telah mengajukan {{.TypeName}} dengan perihal "{{.Subject}}" untuk keperluan {{.Purpose}}.
+{{if .Body}}
+{{.Body}}
+{{end}}
+Demikian surat ini dibuat untuk dipergunakan sebagaimana mestinya.`
+
+// Data adalah placeholder yang bisa dipakai di template surat, contoh:
+// {{.UserName}}, {{.Number}}, {{.Date}}, {{.Fields.nim}}
+type Data struct {
+	Number     string
+	TypeName   string
+	UserName   string
+	UserEmail  string
+	Subject    string
+	Purpose    string
+	Body       string
+	Fields     map[string]interface{}
+	Date       string
+	IssuedAt   time.Time
+	SignerName string
+	SignerRole string
+	VerifyURL  string
+}
+
+var months = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// FormatDate menulis tanggal dalam format Indonesia, misal "17 Agustus 2026"
+func FormatDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+}
+
+// withDate mengisi Date dari IssuedAt kalau belum diisi
+func (data Data) withDate() Data {
+	if data.Date == "" && !data.IssuedAt.IsZero() {
+		data.Date = FormatDate(data.IssuedAt)
+	}
+	return data
+}
+
+// ParseTemplate memvalidasi template surat (dipakai saat admin menyimpan jenis surat)
+func ParseTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate
+	}
+	return template.New("letter").Option("missingkey=zero").Parse(text)
+}
+
+// Render mengisi template surat dengan data surat
+func Render(text string, data Data) (string, error) {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	data = data.withDate()
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
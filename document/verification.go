@@ -0,0 +1,16 @@
+package document
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// NewVerificationToken membuat token acak (192 bit) yang tidak bisa ditebak
+// untuk URL verifikasi keaslian surat
+func NewVerificationToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ===============================
+// Penulis baris laporan
+// ===============================
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// RowWriter menulis laporan baris demi baris sehingga data besar bisa di-stream
+type RowWriter interface {
+	WriteRow(cells []string) error
+	// Flush mengirim baris yang sudah ditulis ke writer tujuan
+	Flush() error
+	// Close menutup laporan (wajib dipanggil agar file valid)
+	Close() error
+}
+
+// New membuat RowWriter sesuai format (csv / xlsx)
+func New(format string, w io.Writer, sheet string) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSV(w)
+	case FormatXLSX:
+		return NewXLSX(w, sheet)
+	}
+	return nil, fmt.Errorf("Format export '%s' tidak dikenali (csv/xlsx)", format)
+}
+
+// ContentType mengembalikan MIME type file export
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// flushOutput ikut mengirim data ke client kalau writer tujuan mendukung Flush (misal response HTTP)
+func flushOutput(out io.Writer) {
+	if f, ok := out.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+}
+
+// escapeFormula mencegah CSV injection: sel yang diawali =, +, -, @, tab atau carriage
+// return diberi awalan ' supaya Excel / Sheets membacanya sebagai teks, bukan rumus.
+// Hanya untuk CSV; sel XLSX ditulis sebagai inline string yang tidak pernah dievaluasi.
+func escapeFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// csvWriter adalah RowWriter untuk CSV
+type csvWriter struct {
+	out io.Writer
+	w   *csv.Writer
+}
+
+// NewCSV membuat penulis CSV. BOM UTF-8 ditulis di awal supaya Excel membaca karakter non-ASCII dengan benar.
+func NewCSV(w io.Writer) (RowWriter, error) {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{out: w, w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteRow(cells []string) error {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escapeFormula(cell)
+	}
+	return c.w.Write(escaped)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	flushOutput(c.out)
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
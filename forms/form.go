@@ -0,0 +1,191 @@
+package forms
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"sanbercode-golang-batch-70-final-project/models"
+)
+
+// ===============================
+// Tipe isian yang didukung
+// ===============================
+
+const (
+	TypeText   = "text"
+	TypeDate   = "date"
+	TypeNumber = "number"
+	TypeSelect = "select"
+
+	// DateLayout adalah format tanggal yang diterima untuk isian bertipe date
+	DateLayout = "2006-01-02"
+)
+
+// Errors berisi pesan error per nama field
+type Errors map[string]string
+
+// ValidateSchema memeriksa definisi form sebelum disimpan ke jenis surat
+func ValidateSchema(schema models.FormSchema) Errors {
+	errs := Errors{}
+	seen := map[string]bool{}
+
+	for i, f := range schema {
+		key := f.Name
+		if key == "" {
+			key = fmt.Sprintf("[%d]", i)
+			errs[key] = "Nama field wajib diisi"
+			continue
+		}
+		if seen[f.Name] {
+			errs[key] = "Nama field tidak boleh duplikat"
+			continue
+		}
+		seen[f.Name] = true
+
+		switch f.Type {
+		case TypeText, TypeDate, TypeNumber:
+		case TypeSelect:
+			if len(f.Options) == 0 {
+				errs[key] = "Field select wajib punya options"
+				continue
+			}
+		default:
+			errs[key] = fmt.Sprintf("Tipe field '%s' tidak dikenali (text/date/number/select)", f.Type)
+			continue
+		}
+
+		if f.Pattern != "" {
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				errs[key] = "Pattern regex tidak valid: " + err.Error()
+				continue
+			}
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			errs[key] = "Nilai min tidak boleh lebih besar dari max"
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ValidateValues memeriksa isian surat terhadap form schema jenis suratnya.
+// Jenis surat tanpa schema menerima isian apa saja.
+func ValidateValues(schema models.FormSchema, values models.JSONMap) Errors {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	errs := Errors{}
+	known := map[string]bool{}
+
+	for _, f := range schema {
+		known[f.Name] = true
+
+		raw, ok := values[f.Name]
+		if !ok || raw == nil || raw == "" {
+			if f.Required {
+				errs[f.Name] = fmt.Sprintf("%s wajib diisi", label(f))
+			}
+			continue
+		}
+
+		if msg := validateValue(f, raw); msg != "" {
+			errs[f.Name] = msg
+		}
+	}
+
+	for name := range values {
+		if !known[name] {
+			errs[name] = "Field tidak dikenal untuk jenis surat ini"
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateValue(f models.FormField, raw interface{}) string {
+	switch f.Type {
+	case TypeNumber:
+		n, ok := toNumber(raw)
+		if !ok {
+			return fmt.Sprintf("%s harus berupa angka", label(f))
+		}
+		if f.Min != nil && n < *f.Min {
+			return fmt.Sprintf("%s minimal %v", label(f), *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return fmt.Sprintf("%s maksimal %v", label(f), *f.Max)
+		}
+		return ""
+
+	case TypeDate:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Sprintf("%s harus berupa tanggal (YYYY-MM-DD)", label(f))
+		}
+		if _, err := time.Parse(DateLayout, s); err != nil {
+			return fmt.Sprintf("%s harus berupa tanggal (YYYY-MM-DD)", label(f))
+		}
+		return ""
+
+	case TypeSelect:
+		s, ok := raw.(string)
+		if ok {
+			for _, opt := range f.Options {
+				if s == opt {
+					return ""
+				}
+			}
+		}
+		return fmt.Sprintf("%s harus salah satu dari %v", label(f), f.Options)
+
+	default: // text
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Sprintf("%s harus berupa teks", label(f))
+		}
+		length := float64(utf8.RuneCountInString(s))
+		if f.Min != nil && length < *f.Min {
+			return fmt.Sprintf("%s minimal %v karakter", label(f), *f.Min)
+		}
+		if f.Max != nil && length > *f.Max {
+			return fmt.Sprintf("%s maksimal %v karakter", label(f), *f.Max)
+		}
+		if f.Pattern != "" {
+			if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(s) {
+				return fmt.Sprintf("%s tidak sesuai format", label(f))
+			}
+		}
+		return ""
+	}
+}
+
+// toNumber menerima angka JSON maupun string angka (misal dari form multipart)
+func toNumber(raw interface{}) (float64, bool) {
+	switch v := raw.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func label(f models.FormField) string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.Name
+}
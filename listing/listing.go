@@ -0,0 +1,202 @@
+package listing
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ===============================
+// Pagination & sorting
+// ===============================
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Spec mendeskripsikan kemampuan daftar sebuah endpoint.
+// Semua kolom ditulis lengkap dengan nama tabel (misal "users.name").
+type Spec struct {
+	Sortable    map[string]string // field di query sort -> kolom
+	DefaultSort string
+	Filters     map[string]string // parameter filter -> kolom (nilai dipisah koma = salah satu)
+	Searchable  []string          // kolom yang dicari dengan parameter q
+	SearchJoin  string            // JOIN tambahan yang dibutuhkan kolom pencarian
+	Key         string            // primary key sebagai pengurut terakhir, kosong = Sortable["id"]
+}
+
+// filter adalah satu filter kolom hasil parsing
+type filter struct {
+	column string
+	values []string
+}
+
+// Params adalah parameter daftar hasil parsing query string
+type Params struct {
+	Page   int
+	Limit  int
+	Order  string // klausa ORDER BY hasil whitelist, misal "letters.created_at DESC, letters.id ASC"
+	Search string
+
+	spec    Spec
+	filters []filter
+}
+
+// Paging adalah metadata halaman yang dikirim bersama daftar data
+type Paging struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+	NextPage   *int  `json:"next_page"`
+}
+
+// Parse membaca page, limit, sort, q & filter field dari query string.
+// sort diawali "-" untuk urutan menurun, beberapa field dipisah koma.
+func (s Spec) Parse(q url.Values) (Params, error) {
+	p := Params{Page: 1, Limit: DefaultLimit, Search: strings.TrimSpace(q.Get("q")), spec: s}
+
+	if v := q.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return p, fmt.Errorf("Parameter page harus angka >= 1")
+		}
+		p.Page = page
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return p, fmt.Errorf("Parameter limit harus angka 1 - %d", MaxLimit)
+		}
+		p.Limit = limit
+	}
+
+	sortParam := q.Get("sort")
+	if sortParam == "" {
+		sortParam = s.DefaultSort
+	}
+	key := s.Key
+	if key == "" {
+		key = s.Sortable["id"]
+	}
+	var order []string
+	sorted := map[string]bool{}
+	for _, field := range strings.Split(sortParam, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		dir := "ASC"
+		if strings.HasPrefix(field, "-") {
+			field, dir = field[1:], "DESC"
+		}
+		column, ok := s.Sortable[field]
+		if !ok {
+			return p, fmt.Errorf("Tidak bisa mengurutkan berdasarkan '%s'", field)
+		}
+		order = append(order, column+" "+dir)
+		sorted[column] = true
+	}
+	// primary key selalu jadi pengurut terakhir supaya baris dengan nilai sort sama
+	// tidak berpindah / dobel antar halaman
+	if key != "" && !sorted[key] {
+		order = append(order, key+" ASC")
+	}
+	p.Order = strings.Join(order, ", ")
+
+	// urutkan nama parameter supaya query yang dihasilkan selalu sama
+	names := make([]string, 0, len(s.Filters))
+	for name := range s.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := strings.TrimSpace(q.Get(name))
+		if v == "" {
+			continue
+		}
+		var values []string
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		p.filters = append(p.filters, filter{column: s.Filters[name], values: values})
+	}
+
+	return p, nil
+}
+
+// Apply menerapkan filter field & pencarian q ke query
+func (p Params) Apply(db *gorm.DB) *gorm.DB {
+	for _, f := range p.filters {
+		db = db.Where(f.column+" IN ?", f.values)
+	}
+	if p.Search != "" && len(p.spec.Searchable) > 0 {
+		if p.spec.SearchJoin != "" {
+			db = db.Joins(p.spec.SearchJoin)
+		}
+		like := LikePattern(p.Search)
+		conds := make([]string, len(p.spec.Searchable))
+		args := make([]interface{}, len(p.spec.Searchable))
+		for i, column := range p.spec.Searchable {
+			conds[i] = column + " LIKE ?"
+			args[i] = like
+		}
+		db = db.Where(strings.Join(conds, " OR "), args...)
+	}
+	return db
+}
+
+// Offset menghitung baris awal halaman
+func (p Params) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+// Paginate menerapkan urutan, limit & offset ke query
+func (p Params) Paginate(db *gorm.DB) *gorm.DB {
+	if p.Order != "" {
+		db = db.Order(p.Order)
+	}
+	return db.Limit(p.Limit).Offset(p.Offset())
+}
+
+// NewPaging menyusun metadata halaman dari total data
+func NewPaging(p Params, total int64) Paging {
+	pages := int((total + int64(p.Limit) - 1) / int64(p.Limit))
+	paging := Paging{Page: p.Page, Limit: p.Limit, Total: total, TotalPages: pages}
+	if p.Page < pages {
+		next := p.Page + 1
+		paging.NextPage = &next
+	}
+	return paging
+}
+
+// Find menghitung total data query db lalu mengambil satu halaman ke dest.
+// scopes (misal Preload) hanya dipakai saat mengambil data, tidak saat menghitung.
+func Find(db *gorm.DB, p Params, dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) (Paging, error) {
+	db = db.Session(&gorm.Session{})
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return Paging{}, err
+	}
+	if err := db.Scopes(p.Paginate).Scopes(scopes...).Find(dest).Error; err != nil {
+		return Paging{}, err
+	}
+	return NewPaging(p, total), nil
+}
+
+// LikePattern membungkus kata kunci pencarian untuk LIKE dengan wildcard yang di-escape
+func LikePattern(search string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(search)
+	return "%" + escaped + "%"
+}
@@ -0,0 +1,41 @@
+package listing
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseOrderTiebreaker(t *testing.T) {
+	spec := Spec{
+		Sortable: map[string]string{
+			"id":         "letters.id",
+			"created_at": "letters.created_at",
+		},
+		DefaultSort: "-created_at",
+	}
+
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"", "letters.created_at DESC, letters.id ASC"},
+		{"created_at", "letters.created_at ASC, letters.id ASC"},
+		{"-id", "letters.id DESC"},
+		{"-created_at,id", "letters.created_at DESC, letters.id ASC"},
+	}
+	for _, tt := range tests {
+		p, err := spec.Parse(url.Values{"sort": {tt.sort}})
+		if err != nil {
+			t.Fatalf("sort=%q: %v", tt.sort, err)
+		}
+		if p.Order != tt.want {
+			t.Errorf("sort=%q: Order = %q, mau %q", tt.sort, p.Order, tt.want)
+		}
+	}
+
+	spec.Key = "letters.number"
+	p, _ := spec.Parse(url.Values{})
+	if want := "letters.created_at DESC, letters.number ASC"; p.Order != want {
+		t.Errorf("Key eksplisit: Order = %q, mau %q", p.Order, want)
+	}
+}
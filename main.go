@@ -6,9 +6,12 @@ import (
     "os"
 
     "sanbercode-golang-batch-70-final-project/config"
+    "sanbercode-golang-batch-70-final-project/controllers"
     _ "sanbercode-golang-batch-70-final-project/docs"
     "sanbercode-golang-batch-70-final-project/notification"
+    "sanbercode-golang-batch-70-final-project/outbox"
     "sanbercode-golang-batch-70-final-project/routes"
+    "sanbercode-golang-batch-70-final-project/storage"
 
     "github.com/joho/godotenv"
 )
@@ -28,6 +31,18 @@ func main() {
     // ✅ Koneksi database
     config.ConnectDB()
 
+    // ✅ Storage lampiran (local / S3-compatible)
+    storage.Setup()
+
+    // ✅ Scheduler pengingat & eskalasi SLA review surat (background)
+    controllers.StartSLAScheduler()
+
+    // ✅ Worker pengirim outbox notifikasi (background)
+    outbox.Start(config.DB, outbox.DefaultConfig())
+
+    // ✅ Bot Telegram untuk tombol Terima / Tolak surat (background)
+    controllers.StartTelegramBot()
+
     // ✅ Inisialisasi WhatsApp client (background)
     go func() {
         fmt.Println("🚀 Inisialisasi WhatsApp client...")
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ApprovalStep adalah satu tahap persetujuan pada jenis surat.
+// Approver ditentukan lewat Role (siapa saja dengan role tersebut) atau UserID (user tertentu).
+type ApprovalStep struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	TypeID    uint   `gorm:"index" json:"type_id"`
+	StepOrder int    `json:"step_order"`
+	Name      string `json:"name"`
+	Role      string `json:"role,omitempty"`
+	UserID    *uint  `json:"user_id,omitempty"`
+}
+
+// LetterApproval mencatat keputusan per tahap persetujuan sebuah surat
+type LetterApproval struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	LetterID     uint      `gorm:"index" json:"letter_id"`
+	Revision     int       `json:"revision"`
+	StepOrder    int       `json:"step_order"`
+	StepName     string    `json:"step_name"`
+	ApproverID   uint      `json:"approver_id"`
+	ApproverRole string    `json:"approver_role"`
+	Decision     string    `json:"decision"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Attachment adalah file pendukung (scan KTP, bukti aktif kuliah, dll) pada surat
+type Attachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	LetterID    uint      `gorm:"index" json:"letter_id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `gorm:"size:255" json:"-"`
+	UploadedBy  uint      `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// FormField adalah definisi satu isian form pada jenis surat
+type FormField struct {
+	Name     string   `json:"name" example:"nim"`
+	Label    string   `json:"label" example:"Nomor Induk Mahasiswa"`
+	Type     string   `json:"type" example:"text"` // text, date, number, select
+	Required bool     `json:"required" example:"true"`
+	Pattern  string   `json:"pattern,omitempty" example:"^[0-9]{10}$"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// FormSchema adalah daftar isian form yang disimpan sebagai kolom json
+type FormSchema []FormField
+
+// Value mengubah schema menjadi string JSON saat disimpan ke database
+func (s FormSchema) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan membaca kolom json dari database ke schema
+func (s *FormSchema) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("FormSchema: tipe %T tidak didukung", value)
+	}
+	return json.Unmarshal(data, s)
+}
+
+// GormDataType memberi tahu GORM tipe kolom yang dipakai saat migrate
+func (FormSchema) GormDataType() string {
+	return "json"
+}
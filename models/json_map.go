@@ -0,0 +1,44 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONMap menyimpan objek JSON bebas ke kolom bertipe json
+type JSONMap map[string]interface{}
+
+// Value mengubah map menjadi string JSON saat disimpan ke database
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan membaca kolom json dari database ke map
+func (m *JSONMap) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("JSONMap: tipe %T tidak didukung", value)
+	}
+	return json.Unmarshal(data, m)
+}
+
+// GormDataType memberi tahu GORM tipe kolom yang dipakai saat migrate
+func (JSONMap) GormDataType() string {
+	return "json"
+}
@@ -1,15 +1,47 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"gorm.io/gorm"
+)
 
 type Letter struct {
 	ID          uint       `gorm:"primaryKey" json:"id"`
 	UserID      uint       `json:"user_id"`
 	TypeID      uint       `json:"type_id"`
-	Status      string     `gorm:"type:enum('pending','accepted','rejected');default:'pending'" json:"status"`
+	Subject     string     `gorm:"size:200" json:"subject"`
+	Purpose     string     `gorm:"type:text" json:"purpose"`
+	Body        string     `gorm:"type:text" json:"body"`
+	Fields      JSONMap    `json:"fields"`
+	Revision    int        `gorm:"default:1" json:"revision"`
+	CurrentStep int        `json:"current_step"`
+	AssignedReviewerID *uint `gorm:"index" json:"assigned_reviewer_id"`
+	Status      string     `gorm:"type:varchar(20);default:'submitted'" json:"status"`
 	RejectReason string    `json:"reject_reason"`
+	CancelReason string    `json:"cancel_reason"`
+	Number      *string    `gorm:"size:100;uniqueIndex" json:"number"`
+	IssuedAt    *time.Time `json:"issued_at"`
+	VerificationToken *string `gorm:"size:64;uniqueIndex" json:"verification_token,omitempty"`
+	DueAt       *time.Time `gorm:"index" json:"due_at"`
+	EscalateAt  *time.Time `gorm:"index" json:"escalate_at"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
+	EscalatedAt *time.Time `json:"escalated_at"`
+	Overdue     bool       `gorm:"-" json:"overdue"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	User        User       `gorm:"foreignKey:UserID"`
 	LetterType  LetterType `gorm:"foreignKey:TypeID"`
+	AssignedReviewer *User `gorm:"foreignKey:AssignedReviewerID" json:"assigned_reviewer,omitempty"`
+	Attachments []Attachment `gorm:"foreignKey:LetterID" json:"attachments,omitempty"`
+	Approvals   []LetterApproval `gorm:"foreignKey:LetterID" json:"approvals,omitempty"`
+	Notifications []NotificationOutbox `gorm:"-" json:"notifications,omitempty"` // hanya diisi di detail surat
+}
+
+// AfterFind menandai surat yang masih menunggu keputusan tapi sudah melewati tenggat review
+func (l *Letter) AfterFind(tx *gorm.DB) error {
+	l.Overdue = workflow.IsPending(l.Status) && l.DueAt != nil && time.Now().After(*l.DueAt)
+	return nil
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LetterComment adalah komentar pada surat untuk tanya-jawab pemohon & reviewer.
+// Komentar internal hanya terlihat oleh reviewer & admin.
+type LetterComment struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	LetterID  uint      `gorm:"index" json:"letter_id"`
+	UserID    uint      `json:"user_id"`
+	Body      string    `gorm:"type:text" json:"body"`
+	Internal  bool      `json:"internal"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	User      User      `gorm:"foreignKey:UserID" json:"user"`
+}
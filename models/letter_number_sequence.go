@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// LetterNumberSequence menyimpan nomor urut terakhir per jenis surat & periode
+type LetterNumberSequence struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TypeID    uint      `gorm:"uniqueIndex:idx_sequence_type_period" json:"type_id"`
+	Period    string    `gorm:"size:10;uniqueIndex:idx_sequence_type_period" json:"period"`
+	LastValue uint      `json:"last_value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
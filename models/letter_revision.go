@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LetterRevision menyimpan isi surat versi sebelumnya saat surat yang ditolak diajukan ulang
+type LetterRevision struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	LetterID     uint      `gorm:"index" json:"letter_id"`
+	Revision     int       `json:"revision"`
+	TypeID       uint      `json:"type_id"`
+	Subject      string    `gorm:"size:200" json:"subject"`
+	Purpose      string    `gorm:"type:text" json:"purpose"`
+	Body         string    `gorm:"type:text" json:"body"`
+	Fields       JSONMap   `json:"fields"`
+	RejectReason string    `json:"reject_reason"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LetterStatusHistory mencatat setiap perubahan pada surat (audit trail)
+type LetterStatusHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	LetterID  uint      `gorm:"index" json:"letter_id"`
+	ActorID   uint      `json:"actor_id"`
+	ActorRole string    `json:"actor_role"`
+	OldStatus string    `json:"old_status"`
+	NewStatus string    `json:"new_status"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
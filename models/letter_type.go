@@ -1,7 +1,26 @@
 package models
 
 type LetterType struct {
-	ID          uint   `gorm:"primaryKey" json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	FormSchema  FormSchema `json:"form_schema"`
+	Template    string     `gorm:"type:text" json:"template"`
+
+	// Penomoran surat resmi, contoh 001/SK/X/2026 (kode unik per jenis surat)
+	NumberCode    string `gorm:"size:20;uniqueIndex" json:"number_code"`
+	NumberFormat  string `gorm:"size:100" json:"number_format"`
+	NumberReset   string `gorm:"type:varchar(10);default:'yearly'" json:"number_reset"`
+	NumberPadding int    `gorm:"default:3" json:"number_padding"`
+
+	// Pembagian surat ke reviewer: round_robin, least_loaded atau manual
+	AssignmentStrategy     string `gorm:"type:varchar(20);default:'round_robin'" json:"assignment_strategy"`
+	LastAssignedReviewerID *uint  `json:"-"`
+
+	// Tenggat review dalam jam kerja (0 = tanpa SLA) & batas eskalasi ke admin sejak surat dikirim
+	ReviewSLAHours  int `json:"review_sla_hours"`
+	EscalationHours int `json:"escalation_hours"`
+
+	// Rantai persetujuan berurutan (kosong = cukup satu keputusan reviewer)
+	ApprovalSteps []ApprovalStep `gorm:"foreignKey:TypeID" json:"approval_steps"`
 }
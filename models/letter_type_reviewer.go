@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// LetterTypeReviewer adalah anggota pool reviewer sebuah jenis surat.
+// Kalau pool kosong, surat dibagikan ke semua user ber-role reviewer.
+type LetterTypeReviewer struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TypeID    uint      `gorm:"uniqueIndex:idx_type_reviewer" json:"type_id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_type_reviewer" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// NotificationDelivery mencatat satu percobaan pengiriman notifikasi ke provider
+// (berhasil maupun gagal), untuk menelusuri apakah pesan benar-benar sampai.
+type NotificationDelivery struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	OutboxID          uint      `gorm:"index" json:"outbox_id"`
+	LetterID          *uint     `gorm:"index" json:"letter_id"`
+	UserID            uint      `gorm:"index" json:"user_id"`
+	Channel           string    `gorm:"size:32" json:"channel"`
+	Recipient         string    `json:"recipient"`
+	Message           string    `gorm:"type:text" json:"message"`
+	Attempt           int       `json:"attempt"`
+	Status            string    `gorm:"size:16;index" json:"status"` // sent / failed
+	ProviderMessageID string    `json:"provider_message_id"`
+	Error             string    `gorm:"type:text" json:"error"`
+	StartedAt         time.Time `json:"started_at"`
+	FinishedAt        time.Time `json:"finished_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// NotificationOutbox adalah satu notifikasi yang menunggu dikirim lewat satu channel.
+// Baris ditulis di transaksi yang sama dengan perubahan surat lalu dikirim worker outbox,
+// sehingga notifikasi tidak hilang walau server restart. Actions berisi tombol aksi (JSON),
+// misal Terima / Tolak, yang hanya ditampilkan channel interaktif.
+type NotificationOutbox struct {
+	ID            uint                   `gorm:"primaryKey" json:"id"`
+	LetterID      *uint                  `gorm:"index" json:"letter_id"` // null untuk pesan ringkasan beberapa surat (lihat NotificationOutboxLetter)
+	UserID        uint                   `gorm:"index" json:"user_id"`
+	Channel       string                 `gorm:"size:32" json:"channel"`
+	Recipient     string                 `json:"recipient"`
+	Message       string                 `gorm:"type:text" json:"message"`
+	Actions       string                 `gorm:"type:text" json:"actions,omitempty"`
+	Status        string                 `gorm:"size:16;index:idx_outbox_due,priority:1;default:'pending'" json:"status"` // pending / processing / sent / dead
+	Attempts      int                    `json:"attempts"`
+	NextAttemptAt time.Time              `gorm:"index:idx_outbox_due,priority:2" json:"next_attempt_at"`
+	LastError     string                 `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time             `json:"sent_at"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	Deliveries    []NotificationDelivery `gorm:"foreignKey:OutboxID" json:"deliveries,omitempty"`
+}
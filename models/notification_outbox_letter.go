@@ -0,0 +1,8 @@
+package models
+
+// NotificationOutboxLetter menghubungkan notifikasi ringkasan (misal hasil keputusan massal)
+// dengan setiap surat yang dibahas, supaya pesan tersebut ikut tampil di detail surat.
+type NotificationOutboxLetter struct {
+	OutboxID uint `gorm:"primaryKey" json:"outbox_id"`
+	LetterID uint `gorm:"primaryKey;index" json:"letter_id"`
+}
@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/models"
+)
+
+// ===============================
+// Registry channel notifikasi
+// ===============================
+
+// Notifier adalah satu channel pengiriman notifikasi (Telegram, WhatsApp, ...)
+type Notifier interface {
+	// Name adalah nama unik channel, misal "telegram"
+	Name() string
+	// Send mengirim pesan ke recipient (chat ID, nomor, dsb. sesuai channel)
+	Send(ctx context.Context, recipient, message string) error
+}
+
+// ReceiptNotifier adalah Notifier yang bisa mengembalikan ID pesan dari provider
+type ReceiptNotifier interface {
+	Notifier
+	SendWithReceipt(ctx context.Context, recipient, message string) (messageID string, err error)
+}
+
+// Action adalah tombol aksi pada pesan, misal Terima / Tolak surat.
+// Data dikirim balik oleh channel interaktif saat tombol ditekan.
+type Action struct {
+	Label string `json:"label"`
+	Data  string `json:"data"`
+}
+
+// ActionNotifier adalah Notifier interaktif yang bisa menempelkan tombol aksi pada pesan
+type ActionNotifier interface {
+	Notifier
+	SendWithActions(ctx context.Context, recipient, message string, actions []Action) (messageID string, err error)
+}
+
+// RateLimitedError dikembalikan channel yang belum boleh mengirim sebelum batas waktu
+// pemanggil habis (rate limit lokal atau retry_after dari provider). Pesan belum dicoba
+// dikirim, jadi pemanggil cukup menjadwalkan ulang setelah RetryAt.
+type RateLimitedError struct {
+	RetryAt time.Time
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("Rate limit, coba lagi setelah %s", e.RetryAt.Format(time.RFC3339))
+}
+
+// Deliver mengirim pesan lewat n dan mengembalikan ID pesan dari provider
+// (kosong kalau channel tidak menyediakannya). Tombol aksi hanya dipakai
+// channel interaktif; channel lain menerima teksnya saja.
+func Deliver(ctx context.Context, n Notifier, recipient, message string, actions []Action) (string, error) {
+	if a, ok := n.(ActionNotifier); ok && len(actions) > 0 {
+		return a.SendWithActions(ctx, recipient, message, actions)
+	}
+	if r, ok := n.(ReceiptNotifier); ok {
+		return r.SendWithReceipt(ctx, recipient, message)
+	}
+	return "", n.Send(ctx, recipient, message)
+}
+
+// RecipientFunc mengambil tujuan pengiriman dari setting user.
+// ok bernilai false kalau user tidak mengaktifkan channel tersebut.
+type RecipientFunc func(s models.Setting) (recipient string, ok bool)
+
+// Delivery adalah satu pengiriman: channel beserta tujuannya
+type Delivery struct {
+	Notifier  Notifier
+	Recipient string
+}
+
+type channel struct {
+	notifier  Notifier
+	recipient RecipientFunc
+}
+
+var (
+	channelsMu sync.RWMutex
+	channels   []channel
+)
+
+func init() {
+	Register(TelegramNotifier{}, telegramRecipient)
+	Register(WhatsAppNotifier{}, whatsAppRecipient)
+}
+
+// Register mendaftarkan channel notifikasi. Channel dengan nama yang sama akan diganti,
+// sehingga channel bawaan bisa ditukar dengan notifier palsu saat pengujian.
+func Register(n Notifier, recipient RecipientFunc) {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+
+	for i, ch := range channels {
+		if ch.notifier.Name() == n.Name() {
+			channels[i] = channel{notifier: n, recipient: recipient}
+			return
+		}
+	}
+	channels = append(channels, channel{notifier: n, recipient: recipient})
+}
+
+// Unregister menghapus channel notifikasi berdasarkan nama
+func Unregister(name string) {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+
+	for i, ch := range channels {
+		if ch.notifier.Name() == name {
+			channels = append(channels[:i], channels[i+1:]...)
+			return
+		}
+	}
+}
+
+// Notifiers mengembalikan semua channel yang terdaftar sesuai urutan pendaftaran
+func Notifiers() []Notifier {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+
+	list := make([]Notifier, len(channels))
+	for i, ch := range channels {
+		list[i] = ch.notifier
+	}
+	return list
+}
+
+// Deliveries mengembalikan channel yang diaktifkan user beserta tujuan pengirimannya
+func Deliveries(s models.Setting) []Delivery {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+
+	var list []Delivery
+	for _, ch := range channels {
+		if recipient, ok := ch.recipient(s); ok {
+			list = append(list, Delivery{Notifier: ch.notifier, Recipient: recipient})
+		}
+	}
+	return list
+}
+
+// Lookup mencari channel terdaftar berdasarkan nama
+func Lookup(name string) (Notifier, bool) {
+	channelsMu.RLock()
+	defer channelsMu.RUnlock()
+
+	for _, ch := range channels {
+		if ch.notifier.Name() == name {
+			return ch.notifier, true
+		}
+	}
+	return nil, false
+}
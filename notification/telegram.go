@@ -1,39 +1,343 @@
 package notification
 
 import (
+    "context"
+    "errors"
     "fmt"
     "log"
+    "net/http"
     "os"
     "strconv"
+    "sync"
+    "time"
 
     tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+    "sanbercode-golang-batch-70-final-project/models"
 )
 
-// SendTelegram kirim pesan ke Telegram berdasarkan token dan chatID dari env
-func SendTelegram(chatID, message string) {
+// ===============================
+// Client Telegram
+// ===============================
+
+// Batas kirim Telegram: sekitar 30 pesan/detik untuk satu bot, 1 pesan/detik per chat
+// pribadi dan 20 pesan/menit per grup. Default dibuat sedikit di bawah batas tersebut.
+const (
+    defaultTelegramQueueSize = 100
+    defaultTelegramRate      = 25 // pesan per detik untuk semua chat
+    telegramChatInterval     = time.Second
+    telegramGroupInterval    = 3 * time.Second
+
+    // Batas waktu satu request HTTP ke Telegram. Pengiriman berjalan satu per satu, jadi
+    // request yang menggantung tidak boleh menahan antrean semua chat terlalu lama.
+    // Client untuk membaca update dibuat terpisah karena long polling-nya menunggu hingga 30 detik.
+    telegramSendTimeout    = 10 * time.Second
+    telegramUpdatesTimeout = time.Minute
+)
+
+var (
+    telegramOnce   sync.Once
+    telegramClient *telegramSender
+)
+
+// telegramJob adalah satu pesan di antrean kirim beserta tempat hasilnya
+type telegramJob struct {
+    ctx    context.Context
+    chatID int64
+    msg    tgbotapi.Chattable
+    done   chan telegramResult
+}
+
+type telegramResult struct {
+    msg tgbotapi.Message
+    err error
+}
+
+// telegramSender memakai satu BotAPI untuk semua pesan. Pesan masuk ke antrean terbatas
+// dan dikirim satu per satu oleh satu goroutine yang menjaga rate limit global & per chat,
+// termasuk menunggu retry_after kalau Telegram membalas 429. Pesan yang gilirannya baru
+// tiba setelah batas waktu pemanggil dijawab dengan *RateLimitedError.
+type telegramSender struct {
+    mu      sync.Mutex // menjaga bot & updates
+    bot     *tgbotapi.BotAPI
+    updates *tgbotapi.BotAPI
+
+    queue          chan telegramJob
+    globalInterval time.Duration
+
+    // hanya diakses goroutine run
+    pending    []telegramJob // pesan yang sudah diambil dari queue, menunggu giliran
+    nextGlobal time.Time
+    nextChat   map[int64]time.Time
+}
+
+// telegram mengembalikan client Telegram bersama, dibuat & dijalankan sekali saja.
+// Ukuran antrean & rate global diatur lewat env TELEGRAM_QUEUE_SIZE & TELEGRAM_RATE.
+func telegram() *telegramSender {
+    telegramOnce.Do(func() {
+        size := defaultTelegramQueueSize
+        if n, err := strconv.Atoi(os.Getenv("TELEGRAM_QUEUE_SIZE")); err == nil && n > 0 {
+            size = n
+        }
+        rate := defaultTelegramRate
+        if n, err := strconv.Atoi(os.Getenv("TELEGRAM_RATE")); err == nil && n > 0 {
+            rate = n
+        }
+
+        telegramClient = &telegramSender{
+            queue:          make(chan telegramJob, size),
+            globalInterval: time.Second / time.Duration(rate),
+            nextChat:       map[int64]time.Time{},
+        }
+        go telegramClient.run()
+    })
+    return telegramClient
+}
+
+// api mengembalikan BotAPI untuk mengirim pesan. Koneksi (getMe) hanya dilakukan sekali;
+// kalau gagal akan dicoba lagi pada pengiriman berikutnya.
+func (t *telegramSender) api() (*tgbotapi.BotAPI, error) {
+    return t.client(&t.bot, telegramSendTimeout)
+}
+
+// updatesAPI mengembalikan BotAPI untuk long polling update (tombol & balasan)
+func (t *telegramSender) updatesAPI() (*tgbotapi.BotAPI, error) {
+    return t.client(&t.updates, telegramUpdatesTimeout)
+}
+
+func (t *telegramSender) client(bot **tgbotapi.BotAPI, timeout time.Duration) (*tgbotapi.BotAPI, error) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    if *bot != nil {
+        return *bot, nil
+    }
     token := os.Getenv("TELEGRAM_TOKEN")
     if token == "" {
-        log.Println("TELEGRAM_TOKEN belum diatur di .env")
-        return
+        return nil, errors.New("TELEGRAM_TOKEN belum diatur di .env")
+    }
+    b, err := tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, &http.Client{Timeout: timeout})
+    if err != nil {
+        return nil, fmt.Errorf("Gagal konek Telegram: %w", err)
+    }
+    *bot = b
+    return b, nil
+}
+
+// send memasukkan pesan ke antrean lalu menunggu hasilnya.
+// Kalau antrean penuh, pemanggil menunggu sampai ada tempat atau ctx habis.
+func (t *telegramSender) send(ctx context.Context, chatID int64, msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+    job := telegramJob{ctx: ctx, chatID: chatID, msg: msg, done: make(chan telegramResult, 1)}
+    select {
+    case t.queue <- job:
+    case <-ctx.Done():
+        return tgbotapi.Message{}, fmt.Errorf("Antrean Telegram penuh: %w", ctx.Err())
+    }
+
+    select {
+    case r := <-job.done:
+        return r.msg, r.err
+    case <-ctx.Done():
+        return tgbotapi.Message{}, ctx.Err()
+    }
+}
+
+// run menjadwalkan antrean: setiap pesan dikirim begitu gilirannya (rate limit global
+// & per chat) tiba, sehingga chat yang sedang dibatasi tidak menahan pesan ke chat lain
+func (t *telegramSender) run() {
+    for {
+        if len(t.pending) == 0 {
+            t.pending = append(t.pending, <-t.queue)
+            continue
+        }
+
+        i, at, ok := t.nextJob()
+        if !ok {
+            continue
+        }
+        if delay := time.Until(at); delay > 0 {
+            timer := time.NewTimer(delay)
+            select {
+            case job := <-t.queue:
+                // pesan baru mungkin lebih cepat gilirannya, jadwal dihitung ulang
+                timer.Stop()
+                t.pending = append(t.pending, job)
+                continue
+            case <-t.pending[i].ctx.Done():
+                // pemanggil sudah berhenti menunggu, pesan dibuang di nextJob
+                timer.Stop()
+                continue
+            case <-timer.C:
+            }
+        }
+
+        job := t.pending[i]
+        t.pending = append(t.pending[:i], t.pending[i+1:]...)
+        msg, retry, err := t.deliver(job)
+        if retry {
+            t.requeueFront(job)
+            continue
+        }
+        job.done <- telegramResult{msg: msg, err: err}
     }
+}
+
+// requeueFront mengembalikan pesan yang ditahan 429 ke depan antrean chat-nya,
+// supaya tidak didahului pesan berikutnya untuk chat yang sama
+func (t *telegramSender) requeueFront(job telegramJob) {
+    at := len(t.pending)
+    for i, pending := range t.pending {
+        if pending.chatID == job.chatID {
+            at = i
+            break
+        }
+    }
+    t.pending = append(t.pending, telegramJob{})
+    copy(t.pending[at+1:], t.pending[at:])
+    t.pending[at] = job
+}
+
+// nextJob memilih pesan yang paling cepat gilirannya (urutan masuk dipertahankan untuk
+// chat yang sama). Pesan yang ctx-nya habis, atau gilirannya jatuh setelah batas waktu
+// pemanggil, langsung dijawab dengan error dan dibuang dari antrean.
+func (t *telegramSender) nextJob() (int, time.Time, bool) {
+    best, bestAt := -1, time.Time{}
+    kept := t.pending[:0]
+    for _, job := range t.pending {
+        at := t.readyAt(job.chatID)
+        if err := job.ctx.Err(); err != nil {
+            job.done <- telegramResult{err: err}
+            continue
+        }
+        if deadline, ok := job.ctx.Deadline(); ok && deadline.Before(at) {
+            job.done <- telegramResult{err: &RateLimitedError{RetryAt: at}}
+            continue
+        }
+        if best < 0 || at.Before(bestAt) {
+            best, bestAt = len(kept), at
+        }
+        kept = append(kept, job)
+    }
+    t.pending = kept
+    return best, bestAt, best >= 0
+}
+
+// readyAt adalah waktu paling cepat sebuah pesan ke chatID boleh dikirim
+func (t *telegramSender) readyAt(chatID int64) time.Time {
+    at := t.nextGlobal
+    if next := t.nextChat[chatID]; next.After(at) {
+        at = next
+    }
+    return at
+}
 
-    bot, err := tgbotapi.NewBotAPI(token)
+// deliver mengirim satu pesan yang gilirannya sudah tiba. retry bernilai true kalau
+// Telegram meminta menunggu (429 retry_after); pesan dikembalikan ke antrean dan
+// dijadwalkan ulang setelah jeda tersebut.
+func (t *telegramSender) deliver(job telegramJob) (tgbotapi.Message, bool, error) {
+    bot, err := t.api()
     if err != nil {
-        log.Println("Gagal konek Telegram:", err)
-        return
+        return tgbotapi.Message{}, false, err
     }
 
+    t.reserve(job.chatID)
+    sent, err := bot.Send(job.msg)
+    var tgErr *tgbotapi.Error
+    if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+        // flood control berlaku untuk seluruh bot, jadi semua pesan ikut ditahan
+        retryAt := time.Now().Add(time.Duration(tgErr.RetryAfter) * time.Second)
+        if retryAt.After(t.nextGlobal) {
+            t.nextGlobal = retryAt
+        }
+        log.Printf("Telegram membatasi pengiriman, menunggu %d detik", tgErr.RetryAfter)
+        return tgbotapi.Message{}, true, nil
+    }
+    return sent, false, err
+}
+
+// reserve mencatat giliran kirim berikutnya sesuai rate limit global & per chat
+func (t *telegramSender) reserve(chatID int64) {
+    now := time.Now()
+    t.nextGlobal = now.Add(t.globalInterval)
+    interval := telegramChatInterval
+    if chatID < 0 { // grup & channel punya ID negatif
+        interval = telegramGroupInterval
+    }
+    t.nextChat[chatID] = now.Add(interval)
+
+    // buang catatan chat yang sudah lewat supaya map tidak terus membesar
+    if len(t.nextChat) > 1000 {
+        for id, next := range t.nextChat {
+            if next.Before(now) {
+                delete(t.nextChat, id)
+            }
+        }
+    }
+}
+
+// ===============================
+// Notifier Telegram
+// ===============================
+
+// TelegramNotifier mengirim notifikasi lewat bot Telegram (token dari env TELEGRAM_TOKEN)
+type TelegramNotifier struct{}
+
+func (TelegramNotifier) Name() string { return "telegram" }
+
+// Send kirim pesan ke chatID Telegram
+func (t TelegramNotifier) Send(ctx context.Context, chatID, message string) error {
+    _, err := t.SendWithReceipt(ctx, chatID, message)
+    return err
+}
+
+// SendWithReceipt kirim pesan ke chatID Telegram dan mengembalikan message_id dari Telegram
+func (t TelegramNotifier) SendWithReceipt(ctx context.Context, chatID, message string) (string, error) {
+    return t.SendWithActions(ctx, chatID, message, nil)
+}
+
+// SendWithActions kirim pesan ke chatID Telegram dengan tombol inline (satu baris)
+func (TelegramNotifier) SendWithActions(ctx context.Context, chatID, message string, actions []Action) (string, error) {
     id, err := strconv.ParseInt(chatID, 10, 64)
     if err != nil {
-        log.Println("ChatID tidak valid:", chatID)
-        return
+        return "", fmt.Errorf("ChatID tidak valid: %s", chatID)
     }
 
     msg := tgbotapi.NewMessage(id, message)
-    _, err = bot.Send(msg)
+    if len(actions) > 0 {
+        row := make([]tgbotapi.InlineKeyboardButton, len(actions))
+        for i, a := range actions {
+            row[i] = tgbotapi.NewInlineKeyboardButtonData(a.Label, a.Data)
+        }
+        msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+    }
+
+    sent, err := SendTelegramMessage(ctx, id, msg)
     if err != nil {
+        return "", err
+    }
+    fmt.Println("Pesan terkirim ke Telegram:", chatID)
+    return strconv.Itoa(sent.MessageID), nil
+}
+
+// SendTelegramMessage mengirim pesan Telegram apa pun (teks, edit pesan, dsb.) lewat
+// antrean bersama sehingga tetap mengikuti rate limit bot
+func SendTelegramMessage(ctx context.Context, chatID int64, msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+    return telegram().send(ctx, chatID, msg)
+}
+
+// TelegramBot mengembalikan BotAPI bersama untuk membaca update dari Telegram (long polling)
+func TelegramBot() (*tgbotapi.BotAPI, error) {
+    return telegram().updatesAPI()
+}
+
+// telegramRecipient memakai chat ID Telegram user kalau notifikasi Telegram diaktifkan
+func telegramRecipient(s models.Setting) (string, bool) {
+    return s.TelegramChatID, s.AllowTelegram == "yes" && s.TelegramChatID != ""
+}
+
+// SendTelegram kirim pesan ke Telegram berdasarkan token dan chatID dari env
+func SendTelegram(chatID, message string) {
+    if err := (TelegramNotifier{}).Send(context.Background(), chatID, message); err != nil {
         log.Println("Gagal kirim pesan Telegram:", err)
-    } else {
-        fmt.Println("Pesan terkirim ke Telegram:", chatID)
     }
 }
@@ -19,6 +19,8 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	_ "github.com/mattn/go-sqlite3"
+
+	"sanbercode-golang-batch-70-final-project/models"
 )
 
 var (
@@ -115,8 +117,19 @@ func initClient() *whatsmeow.Client {
 	return client
 }
 
-// SendWhatsApp kirim pesan teks ke nomor WA tujuan (nomor tanpa + dan dengan kode negara)
-func SendWhatsApp(phone, message string) {
+// WhatsAppNotifier mengirim notifikasi lewat WhatsApp (client WhatsMeow)
+type WhatsAppNotifier struct{}
+
+func (WhatsAppNotifier) Name() string { return "whatsapp" }
+
+// Send kirim pesan teks ke nomor WA tujuan (nomor tanpa + dan dengan kode negara)
+func (w WhatsAppNotifier) Send(ctx context.Context, phone, message string) error {
+	_, err := w.SendWithReceipt(ctx, phone, message)
+	return err
+}
+
+// SendWithReceipt kirim pesan teks ke nomor WA tujuan dan mengembalikan ID pesan WhatsApp
+func (WhatsAppNotifier) SendWithReceipt(ctx context.Context, phone, message string) (string, error) {
 	cli := initClient()
 
 	jid := types.NewJID(phone, "s.whatsapp.net")
@@ -124,10 +137,22 @@ func SendWhatsApp(phone, message string) {
 		Conversation: proto.String(message),
 	}
 
-	_, err := cli.SendMessage(context.Background(), jid, msg)
+	resp, err := cli.SendMessage(ctx, jid, msg)
 	if err != nil {
+		return "", err
+	}
+	fmt.Println("Pesan terkirim ke WA:", phone)
+	return string(resp.ID), nil
+}
+
+// whatsAppRecipient memakai nomor WA user kalau notifikasi WhatsApp diaktifkan
+func whatsAppRecipient(s models.Setting) (string, bool) {
+	return s.WANumber, s.AllowWA == "yes" && s.WANumber != ""
+}
+
+// SendWhatsApp kirim pesan teks ke nomor WA tujuan (nomor tanpa + dan dengan kode negara)
+func SendWhatsApp(phone, message string) {
+	if err := (WhatsAppNotifier{}).Send(context.Background(), phone, message); err != nil {
 		log.Println("Gagal kirim WA:", err)
-	} else {
-		fmt.Println("Pesan terkirim ke WA:", phone)
 	}
 }
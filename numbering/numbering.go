@@ -0,0 +1,107 @@
+package numbering
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ===============================
+// Reset nomor urut
+// ===============================
+
+const (
+	ResetYearly  = "yearly"
+	ResetMonthly = "monthly"
+	ResetNever   = "never"
+
+	// DefaultFormat menghasilkan nomor seperti 001/SK/X/2026
+	DefaultFormat  = "{seq}/{code}/{month_roman}/{year}"
+	DefaultPadding = 3
+)
+
+var romans = [...]string{"I", "II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X", "XI", "XII"}
+
+// RomanMonth mengubah bulan menjadi angka romawi (Oktober = X)
+func RomanMonth(m time.Month) string {
+	return romans[m-1]
+}
+
+// Validate memeriksa format nomor & jenis reset milik jenis surat
+func Validate(format, reset string) error {
+	if format != "" && !strings.Contains(format, "{seq}") {
+		return errors.New("Format nomor surat wajib mengandung {seq}")
+	}
+	if format != "" && !strings.Contains(format, "{code}") {
+		return errors.New("Format nomor surat wajib mengandung {code} supaya tidak bentrok dengan jenis surat lain")
+	}
+	switch reset {
+	case "", ResetYearly, ResetMonthly, ResetNever:
+		return nil
+	}
+	return fmt.Errorf("Reset nomor '%s' tidak dikenali (yearly/monthly/never)", reset)
+}
+
+// Period menentukan kunci periode nomor urut, misal "2026" atau "2026-10"
+func Period(reset string, t time.Time) string {
+	switch reset {
+	case ResetMonthly:
+		return t.Format("2006-01")
+	case ResetNever:
+		return "all"
+	default:
+		return t.Format("2006")
+	}
+}
+
+// Format menyusun nomor surat dari format jenis surat.
+// Placeholder: {seq}, {code}, {month}, {month_roman}, {year}
+func Format(lt models.LetterType, seq uint, t time.Time) string {
+	format := lt.NumberFormat
+	if format == "" {
+		format = DefaultFormat
+	}
+	padding := lt.NumberPadding
+	if padding <= 0 {
+		padding = DefaultPadding
+	}
+
+	return strings.NewReplacer(
+		"{seq}", fmt.Sprintf("%0*d", padding, seq),
+		"{code}", lt.NumberCode,
+		"{month_roman}", RomanMonth(t.Month()),
+		"{month}", fmt.Sprintf("%02d", int(t.Month())),
+		"{year}", t.Format("2006"),
+	).Replace(format)
+}
+
+// Next mengambil nomor urut berikutnya di dalam transaksi tx.
+// Baris sequence dikunci (SELECT ... FOR UPDATE) sehingga approval bersamaan
+// tidak mendapat nomor yang sama, dan rollback transaksi tidak meninggalkan celah.
+func Next(tx *gorm.DB, lt models.LetterType, t time.Time) (string, error) {
+	seq := models.LetterNumberSequence{TypeID: lt.ID, Period: Period(lt.NumberReset, t)}
+
+	// pastikan baris sequence ada (aman kalau dua transaksi membuat bersamaan)
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seq).Error; err != nil {
+		return "", err
+	}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("type_id = ? AND period = ?", seq.TypeID, seq.Period).
+		First(&seq).Error; err != nil {
+		return "", err
+	}
+
+	seq.LastValue++
+	if err := tx.Model(&seq).Update("last_value", seq.LastValue).Error; err != nil {
+		return "", err
+	}
+
+	return Format(lt, seq.LastValue, t), nil
+}
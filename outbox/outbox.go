@@ -0,0 +1,383 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/notification"
+
+	"gorm.io/gorm"
+)
+
+// ===============================
+// Outbox notifikasi
+// ===============================
+
+const (
+	StatusPending    = "pending"    // menunggu dikirim / dicoba ulang
+	StatusProcessing = "processing" // sedang dikirim worker
+	StatusSent       = "sent"       // terkirim
+	StatusDead       = "dead"       // gagal sampai batas percobaan (dead-letter)
+)
+
+// Status satu percobaan di log pengiriman
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// ErrNotRetryable dikembalikan Retry untuk notifikasi yang tidak berstatus dead
+var ErrNotRetryable = errors.New("Hanya notifikasi berstatus dead yang bisa dikirim ulang")
+
+// Config mengatur worker pool outbox
+type Config struct {
+	Workers      int           // jumlah worker pengirim
+	PollInterval time.Duration // jeda antar pengecekan notifikasi yang jatuh tempo
+	BatchSize    int           // batas notifikasi yang diambil per pengecekan (tetap dibatasi jumlah worker menganggur)
+	MaxAttempts  int           // batas percobaan sebelum dipindah ke dead
+	BaseBackoff  time.Duration // jeda setelah percobaan pertama gagal, lalu berlipat dua
+	MaxBackoff   time.Duration // batas atas jeda percobaan ulang
+	SendTimeout  time.Duration // batas waktu satu kali kirim
+	Lease        time.Duration // notifikasi "processing" lebih lama dari ini dianggap macet & diambil ulang
+}
+
+// DefaultConfig membaca konfigurasi dari env OUTBOX_WORKERS, OUTBOX_POLL_INTERVAL & OUTBOX_MAX_ATTEMPTS
+func DefaultConfig() Config {
+	cfg := Config{
+		Workers:      4,
+		PollInterval: 2 * time.Second,
+		BatchSize:    100,
+		MaxAttempts:  6,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   30 * time.Minute,
+		SendTimeout:  30 * time.Second,
+		Lease:        5 * time.Minute,
+	}
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_WORKERS")); err == nil && n > 0 {
+		cfg.Workers = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("OUTBOX_POLL_INTERVAL")); err == nil && d > 0 {
+		cfg.PollInterval = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS")); err == nil && n > 0 {
+		cfg.MaxAttempts = n
+	}
+	return cfg
+}
+
+// Backoff menghitung jeda sebelum percobaan berikutnya setelah attempts kali gagal
+func (cfg Config) Backoff(attempts int) time.Duration {
+	d := cfg.BaseBackoff
+	for i := 1; i < attempts && d < cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > cfg.MaxBackoff {
+		d = cfg.MaxBackoff
+	}
+	return d
+}
+
+// Enqueue menulis pesan ke outbox untuk setiap channel yang diaktifkan user.
+// Panggil dengan tx yang sama dengan perubahan surat; letterID 0 berarti tidak terkait satu surat.
+// Tombol aksi (opsional) hanya ditampilkan channel interaktif seperti Telegram.
+func Enqueue(tx *gorm.DB, letterID uint, s models.Setting, message string, actions ...notification.Action) error {
+	_, err := enqueue(tx, letterID, s, message, actions)
+	return err
+}
+
+// EnqueueSummary menulis satu pesan ringkasan tentang beberapa surat sekaligus dan
+// menautkannya ke setiap surat tersebut (NotificationOutboxLetter)
+func EnqueueSummary(tx *gorm.DB, letterIDs []uint, s models.Setting, message string) error {
+	var letterID uint
+	if len(letterIDs) == 1 {
+		letterID = letterIDs[0]
+	}
+	items, err := enqueue(tx, letterID, s, message, nil)
+	if err != nil {
+		return err
+	}
+
+	var links []models.NotificationOutboxLetter
+	for _, item := range items {
+		for _, id := range letterIDs {
+			links = append(links, models.NotificationOutboxLetter{OutboxID: item.ID, LetterID: id})
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return tx.Create(&links).Error
+}
+
+func enqueue(tx *gorm.DB, letterID uint, s models.Setting, message string, actions []notification.Action) ([]models.NotificationOutbox, error) {
+	var id *uint
+	if letterID != 0 {
+		id = &letterID
+	}
+	encoded := ""
+	if len(actions) > 0 {
+		b, err := json.Marshal(actions)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(b)
+	}
+
+	now := time.Now()
+	var items []models.NotificationOutbox
+	for _, d := range notification.Deliveries(s) {
+		item := models.NotificationOutbox{
+			LetterID:      id,
+			UserID:        s.UserID,
+			Channel:       d.Notifier.Name(),
+			Recipient:     d.Recipient,
+			Message:       message,
+			Actions:       encoded,
+			Status:        StatusPending,
+			NextAttemptAt: now,
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// Retry mengembalikan notifikasi dead ke antrean dengan jatah percobaan baru
+func Retry(db *gorm.DB, id uint) error {
+	res := db.Model(&models.NotificationOutbox{}).
+		Where("id = ? AND status = ?", id, StatusDead).
+		Updates(redrive())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotRetryable
+	}
+	return nil
+}
+
+// RetryDead mengembalikan semua notifikasi dead (opsional per channel) ke antrean
+func RetryDead(db *gorm.DB, channel string) (int64, error) {
+	query := db.Model(&models.NotificationOutbox{}).Where("status = ?", StatusDead)
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+	res := query.Updates(redrive())
+	return res.RowsAffected, res.Error
+}
+
+func redrive() map[string]interface{} {
+	return map[string]interface{}{
+		"status":          StatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}
+}
+
+// ===============================
+// Worker pool
+// ===============================
+
+// Start menjalankan dispatcher yang mengambil notifikasi jatuh tempo
+// dan worker pool yang mengirimkannya (background).
+func Start(db *gorm.DB, cfg Config) {
+	jobs := make(chan models.NotificationOutbox)
+	idle := make(chan struct{}, cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go func() {
+			for {
+				idle <- struct{}{}
+				deliver(db, cfg, <-jobs)
+			}
+		}()
+	}
+
+	d := &dispatcher{
+		cfg:  cfg,
+		idle: idle,
+		jobs: jobs,
+		claim: func(limit int) []models.NotificationOutbox {
+			return claimDue(db, cfg, time.Now(), limit)
+		},
+	}
+	go func() {
+		ticker := time.NewTicker(cfg.PollInterval)
+		defer ticker.Stop()
+		for {
+			d.dispatch()
+
+			// cek lagi saat ada worker selesai atau saat jeda polling habis
+			select {
+			case <-idle:
+				d.free++
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// dispatcher membagikan notifikasi jatuh tempo ke worker. Dispatcher hanya mengklaim
+// sebanyak worker yang sedang menganggur, supaya notifikasi yang sudah diklaim langsung
+// dikirim dan tidak kedaluwarsa (Lease) selagi antre lalu diambil ulang instance lain.
+type dispatcher struct {
+	cfg   Config
+	idle  <-chan struct{}                  // satu sinyal per worker yang siap menerima notifikasi
+	jobs  chan<- models.NotificationOutbox // notifikasi yang sudah diklaim untuk worker
+	claim func(limit int) []models.NotificationOutbox
+	free  int // jumlah worker menganggur yang sudah tercatat
+}
+
+// dispatch mencatat worker yang menganggur lalu mengklaim paling banyak sejumlah itu
+// (dibatasi BatchSize) dan menyerahkannya ke worker
+func (d *dispatcher) dispatch() {
+	for drained := false; !drained; {
+		select {
+		case <-d.idle:
+			d.free++
+		default:
+			drained = true
+		}
+	}
+
+	limit := d.free
+	if limit > d.cfg.BatchSize {
+		limit = d.cfg.BatchSize
+	}
+	if limit == 0 {
+		return
+	}
+	for _, item := range d.claim(limit) {
+		d.jobs <- item
+		d.free--
+	}
+}
+
+// claimDue mengambil maksimal limit notifikasi pending yang jatuh tempo (atau processing yang macet)
+// dan menandainya processing. Hanya satu instance yang berhasil mengklaim setiap baris.
+func claimDue(db *gorm.DB, cfg Config, now time.Time, limit int) []models.NotificationOutbox {
+	var due []models.NotificationOutbox
+	if err := db.Where("status IN ? AND next_attempt_at <= ?", []string{StatusPending, StatusProcessing}, now).
+		Order("next_attempt_at").Limit(limit).Find(&due).Error; err != nil {
+		log.Println("Gagal mengambil outbox notifikasi:", err)
+		return nil
+	}
+
+	claimed := due[:0]
+	for _, item := range due {
+		res := db.Model(&models.NotificationOutbox{}).
+			Where("id = ? AND status IN ? AND next_attempt_at <= ?", item.ID, []string{StatusPending, StatusProcessing}, now).
+			Updates(map[string]interface{}{
+				"status":          StatusProcessing,
+				"attempts":        gorm.Expr("attempts + 1"),
+				"next_attempt_at": now.Add(cfg.Lease),
+			})
+		if res.Error != nil {
+			log.Println("Gagal mengklaim outbox notifikasi:", res.Error)
+			continue
+		}
+		if res.RowsAffected == 1 {
+			item.Attempts++
+			claimed = append(claimed, item)
+		}
+	}
+	return claimed
+}
+
+// deliver mengirim satu notifikasi lalu menyimpan hasilnya (lihat settle) dalam satu transaksi
+func deliver(db *gorm.DB, cfg Config, item models.NotificationOutbox) {
+	started := time.Now()
+	messageID, err := send(cfg, item)
+	attempt, updates := settle(cfg, item, messageID, err, started, time.Now())
+
+	txErr := db.Transaction(func(tx *gorm.DB) error {
+		if attempt != nil {
+			if err := tx.Create(attempt).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.NotificationOutbox{}).
+			Where("id = ? AND status = ?", item.ID, StatusProcessing).
+			Updates(updates).Error
+	})
+	if txErr != nil {
+		log.Println("Gagal memperbarui outbox notifikasi:", txErr)
+	}
+}
+
+// settle menyusun hasil satu pengiriman: percobaan untuk log pengiriman dan perubahan baris
+// outbox, yaitu sent, dijadwalkan ulang dengan exponential backoff, atau dead setelah
+// MaxAttempts. Notifikasi yang tertahan rate limit belum dikirim, jadi tidak dicatat sebagai
+// percobaan (attempt nil) dan jatah percobaannya dikembalikan.
+func settle(cfg Config, item models.NotificationOutbox, messageID string, err error, started, now time.Time) (*models.NotificationDelivery, map[string]interface{}) {
+	var limited *notification.RateLimitedError
+	if errors.As(err, &limited) {
+		return nil, map[string]interface{}{
+			"status":          StatusPending,
+			"attempts":        gorm.Expr("attempts - 1"),
+			"next_attempt_at": limited.RetryAt,
+			"last_error":      limited.Error(),
+		}
+	}
+
+	attempt := &models.NotificationDelivery{
+		OutboxID:          item.ID,
+		LetterID:          item.LetterID,
+		UserID:            item.UserID,
+		Channel:           item.Channel,
+		Recipient:         item.Recipient,
+		Message:           item.Message,
+		Attempt:           item.Attempts,
+		Status:            DeliverySent,
+		ProviderMessageID: messageID,
+		StartedAt:         started,
+		FinishedAt:        now,
+	}
+
+	updates := map[string]interface{}{}
+	switch {
+	case err == nil:
+		updates["status"] = StatusSent
+		updates["sent_at"] = now
+		updates["last_error"] = ""
+	case item.Attempts >= cfg.MaxAttempts:
+		updates["status"] = StatusDead
+		updates["last_error"] = err.Error()
+		log.Printf("Notifikasi %d (%s) gagal %d kali, dipindah ke dead: %v", item.ID, item.Channel, item.Attempts, err)
+	default:
+		updates["status"] = StatusPending
+		updates["next_attempt_at"] = now.Add(cfg.Backoff(item.Attempts))
+		updates["last_error"] = err.Error()
+	}
+	if err != nil {
+		attempt.Status = DeliveryFailed
+		attempt.Error = err.Error()
+	}
+	return attempt, updates
+}
+
+// send mengirim notifikasi lewat channel terdaftar dan mengembalikan ID pesan dari provider
+func send(cfg Config, item models.NotificationOutbox) (string, error) {
+	n, ok := notification.Lookup(item.Channel)
+	if !ok {
+		return "", fmt.Errorf("Channel '%s' tidak terdaftar", item.Channel)
+	}
+	var actions []notification.Action
+	if item.Actions != "" {
+		if err := json.Unmarshal([]byte(item.Actions), &actions); err != nil {
+			return "", fmt.Errorf("Tombol aksi tidak valid: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.SendTimeout)
+	defer cancel()
+	return notification.Deliver(ctx, n, item.Recipient, item.Message, actions)
+}
@@ -0,0 +1,525 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/notification"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fakeNotifier adalah channel palsu yang mencatat setiap pesan dan membalas sesuai reply
+type fakeNotifier struct {
+	mu    sync.Mutex
+	sent  []fakeMessage
+	reply func(ctx context.Context) (string, error)
+}
+
+type fakeMessage struct {
+	recipient string
+	message   string
+	actions   []notification.Action
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Send(ctx context.Context, recipient, message string) error {
+	_, err := f.SendWithActions(ctx, recipient, message, nil)
+	return err
+}
+
+func (f *fakeNotifier) SendWithReceipt(ctx context.Context, recipient, message string) (string, error) {
+	return f.SendWithActions(ctx, recipient, message, nil)
+}
+
+func (f *fakeNotifier) SendWithActions(ctx context.Context, recipient, message string, actions []notification.Action) (string, error) {
+	f.mu.Lock()
+	f.sent = append(f.sent, fakeMessage{recipient: recipient, message: message, actions: actions})
+	f.mu.Unlock()
+	return f.reply(ctx)
+}
+
+// registerFake mendaftarkan channel palsu untuk user yang mengaktifkan Telegram
+func registerFake(t *testing.T, reply func(ctx context.Context) (string, error)) *fakeNotifier {
+	t.Helper()
+	f := &fakeNotifier{reply: reply}
+	notification.Register(f, func(s models.Setting) (string, bool) {
+		return fmt.Sprintf("fake-%d", s.UserID), s.AllowTelegram == "yes"
+	})
+	t.Cleanup(func() { notification.Unregister(f.Name()) })
+	return f
+}
+
+func testConfig() Config {
+	return Config{
+		Workers:     1,
+		MaxAttempts: 3,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  time.Minute,
+		SendTimeout: time.Second,
+		Lease:       time.Minute,
+	}
+}
+
+func fakeItem(attempts int, actions ...notification.Action) models.NotificationOutbox {
+	letterID := uint(7)
+	item := models.NotificationOutbox{
+		ID:        42,
+		LetterID:  &letterID,
+		UserID:    3,
+		Channel:   "fake",
+		Recipient: "fake-3",
+		Message:   "📩 Pengajuan surat baru",
+		Status:    StatusProcessing,
+		Attempts:  attempts,
+	}
+	if len(actions) > 0 {
+		b, _ := json.Marshal(actions)
+		item.Actions = string(b)
+	}
+	return item
+}
+
+func TestFakeNotifierIsRouted(t *testing.T) {
+	f := registerFake(t, func(context.Context) (string, error) { return "", nil })
+
+	found := false
+	for _, d := range notification.Deliveries(models.Setting{UserID: 3, AllowTelegram: "yes"}) {
+		if d.Notifier == f {
+			found = d.Recipient == "fake-3"
+		}
+	}
+	if !found {
+		t.Error("channel palsu tidak dipakai untuk user yang mengaktifkannya")
+	}
+	for _, d := range notification.Deliveries(models.Setting{UserID: 3, AllowTelegram: "no"}) {
+		if d.Notifier == f {
+			t.Error("channel palsu dipakai untuk user yang tidak mengaktifkannya")
+		}
+	}
+	if n, ok := notification.Lookup("fake"); !ok || n != f {
+		t.Error("Lookup tidak menemukan channel palsu")
+	}
+}
+
+func TestDeliverySuccess(t *testing.T) {
+	f := registerFake(t, func(context.Context) (string, error) { return "msg-1", nil })
+	cfg := testConfig()
+	actions := []notification.Action{{Label: "✅ Terima", Data: "accept:7"}, {Label: "❌ Tolak", Data: "reject:7"}}
+	item := fakeItem(1, actions...)
+
+	started := time.Now()
+	messageID, err := send(cfg, item)
+	if err != nil || messageID != "msg-1" {
+		t.Fatalf("send = (%q, %v), mau (msg-1, nil)", messageID, err)
+	}
+	if len(f.sent) != 1 {
+		t.Fatalf("notifier menerima %d pesan, mau 1", len(f.sent))
+	}
+	got := f.sent[0]
+	if got.recipient != item.Recipient || got.message != item.Message {
+		t.Errorf("pesan terkirim = %+v", got)
+	}
+	if len(got.actions) != 2 || got.actions[1] != actions[1] {
+		t.Errorf("tombol aksi = %+v, mau %+v", got.actions, actions)
+	}
+
+	now := time.Now()
+	attempt, updates := settle(cfg, item, messageID, err, started, now)
+	if attempt == nil || attempt.Status != DeliverySent || attempt.ProviderMessageID != "msg-1" ||
+		attempt.Attempt != 1 || attempt.OutboxID != item.ID || *attempt.LetterID != 7 || attempt.Error != "" {
+		t.Errorf("log pengiriman = %+v", attempt)
+	}
+	if updates["status"] != StatusSent || updates["sent_at"] != now || updates["last_error"] != "" {
+		t.Errorf("perubahan outbox = %v", updates)
+	}
+}
+
+func TestDeliveryFailure(t *testing.T) {
+	registerFake(t, func(context.Context) (string, error) { return "", errors.New("chat not found") })
+	cfg := testConfig()
+
+	tests := []struct {
+		attempts int
+		status   string
+		backoff  time.Duration // 0 = tidak dijadwalkan ulang
+	}{
+		{1, StatusPending, 30 * time.Second},
+		{2, StatusPending, time.Minute},
+		{3, StatusDead, 0}, // MaxAttempts tercapai
+	}
+	for _, tt := range tests {
+		item := fakeItem(tt.attempts)
+		messageID, err := send(cfg, item)
+		if err == nil {
+			t.Fatal("send seharusnya gagal")
+		}
+
+		now := time.Now()
+		attempt, updates := settle(cfg, item, messageID, err, now, now)
+		if attempt == nil || attempt.Status != DeliveryFailed || attempt.Error != "chat not found" || attempt.Attempt != tt.attempts {
+			t.Errorf("percobaan %d: log pengiriman = %+v", tt.attempts, attempt)
+		}
+		if updates["status"] != tt.status || updates["last_error"] != "chat not found" {
+			t.Errorf("percobaan %d: perubahan outbox = %v", tt.attempts, updates)
+		}
+		next, scheduled := updates["next_attempt_at"]
+		switch {
+		case tt.backoff == 0 && scheduled:
+			t.Errorf("percobaan %d: notifikasi dead tidak boleh dijadwalkan ulang", tt.attempts)
+		case tt.backoff != 0 && next != now.Add(tt.backoff):
+			t.Errorf("percobaan %d: next_attempt_at = %v, mau %v", tt.attempts, next, now.Add(tt.backoff))
+		}
+	}
+}
+
+func TestDeliveryRateLimited(t *testing.T) {
+	retryAt := time.Now().Add(5 * time.Second)
+	registerFake(t, func(context.Context) (string, error) {
+		return "", fmt.Errorf("fake: %w", &notification.RateLimitedError{RetryAt: retryAt})
+	})
+	cfg := testConfig()
+
+	// percobaan terakhir pun tidak dipindah ke dead karena pesan belum dikirim
+	item := fakeItem(cfg.MaxAttempts)
+	messageID, err := send(cfg, item)
+	now := time.Now()
+	attempt, updates := settle(cfg, item, messageID, err, now, now)
+	if attempt != nil {
+		t.Errorf("rate limit tidak boleh dicatat sebagai percobaan, dapat %+v", attempt)
+	}
+	if updates["status"] != StatusPending || updates["next_attempt_at"] != retryAt {
+		t.Errorf("perubahan outbox = %v", updates)
+	}
+	if expr, ok := updates["attempts"].(clause.Expr); !ok || expr.SQL != "attempts - 1" {
+		t.Errorf("jatah percobaan tidak dikembalikan: %v", updates["attempts"])
+	}
+}
+
+func TestDeliveryTimeout(t *testing.T) {
+	registerFake(t, func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	cfg := testConfig()
+	cfg.SendTimeout = 20 * time.Millisecond
+
+	_, err := send(cfg, fakeItem(1))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("send = %v, mau context.DeadlineExceeded", err)
+	}
+}
+
+func TestDeliveryUnknownChannel(t *testing.T) {
+	cfg := testConfig()
+	item := fakeItem(1)
+	item.Channel = "pigeon"
+
+	_, err := send(cfg, item)
+	if err == nil {
+		t.Fatal("channel yang tidak terdaftar seharusnya gagal")
+	}
+	now := time.Now()
+	attempt, updates := settle(cfg, item, "", err, now, now)
+	if attempt.Status != DeliveryFailed || updates["status"] != StatusPending {
+		t.Errorf("log pengiriman = %+v, perubahan outbox = %v", attempt, updates)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := Config{BaseBackoff: 30 * time.Second, MaxBackoff: 30 * time.Minute}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{7, 30 * time.Minute},
+		{20, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := cfg.Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, mau %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// fakeOutboxDB adalah tabel notification_outboxes di memori yang menjalankan dua query
+// milik claimDue (ambil notifikasi jatuh tempo & klaim per baris); query lain ditolak
+type fakeOutboxDB struct {
+	mu     sync.Mutex
+	rows   map[uint]*models.NotificationOutbox
+	limits []int // LIMIT setiap query pengambilan
+
+	// beforeClaim dipanggil sebelum UPDATE klaim, misal untuk meniru instance lain
+	// yang lebih dulu mengklaim baris tersebut
+	beforeClaim func(row *models.NotificationOutbox)
+}
+
+var (
+	selectDuePattern = regexp.MustCompile("^SELECT \\* FROM `notification_outboxes` WHERE status IN \\(\\?(,\\?)*\\) " +
+		"AND next_attempt_at <= \\? ORDER BY next_attempt_at LIMIT \\?$")
+	claimPattern = regexp.MustCompile("^UPDATE `notification_outboxes` SET `attempts`=attempts \\+ 1,`next_attempt_at`=\\?," +
+		"`status`=\\?,`updated_at`=\\? WHERE id = \\? AND status IN \\(\\?(,\\?)*\\) AND next_attempt_at <= \\?$")
+)
+
+func newFakeOutboxDB(t *testing.T, rows ...models.NotificationOutbox) (*fakeOutboxDB, *gorm.DB) {
+	t.Helper()
+	f := &fakeOutboxDB{rows: map[uint]*models.NotificationOutbox{}}
+	for i := range rows {
+		f.rows[rows[i].ID] = &rows[i]
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sql.OpenDB(f), SkipInitializeWithVersion: true}),
+		&gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, db
+}
+
+func (f *fakeOutboxDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeOutboxDB) Driver() driver.Driver                        { return nil }
+
+// matches mengecek kondisi WHERE klaim: status salah satu statuses & next_attempt_at <= cutoff
+func (f *fakeOutboxDB) matches(row *models.NotificationOutbox, statuses []driver.NamedValue, cutoff time.Time) bool {
+	if row.NextAttemptAt.After(cutoff) {
+		return false
+	}
+	for _, status := range statuses {
+		if status.Value == row.Status {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeConn struct{ db *fakeOutboxDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare tidak didukung")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c fakeConn) Commit() error             { return nil }
+func (c fakeConn) Rollback() error           { return nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !selectDuePattern.MatchString(query) {
+		return nil, fmt.Errorf("query tidak dikenal: %s", query)
+	}
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := len(args)
+	cutoff, limit := args[n-2].Value.(time.Time), int(args[n-1].Value.(int64))
+	f.limits = append(f.limits, limit)
+
+	var due []models.NotificationOutbox
+	for _, row := range f.rows {
+		if f.matches(row, args[:n-2], cutoff) {
+			due = append(due, *row)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return &fakeRows{due: due}, nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !claimPattern.MatchString(query) {
+		return nil, fmt.Errorf("query tidak dikenal: %s", query)
+	}
+	f := c.db
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := len(args)
+	row, ok := f.rows[uint(args[3].Value.(int64))]
+	if !ok {
+		return driver.RowsAffected(0), nil
+	}
+	if f.beforeClaim != nil {
+		f.beforeClaim(row)
+	}
+	if !f.matches(row, args[4:n-1], args[n-1].Value.(time.Time)) {
+		return driver.RowsAffected(0), nil
+	}
+	row.Attempts++
+	row.NextAttemptAt = args[0].Value.(time.Time)
+	row.Status = args[1].Value.(string)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeRows struct {
+	due []models.NotificationOutbox
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "channel", "status", "attempts", "next_attempt_at"}
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.due) == 0 {
+		return io.EOF
+	}
+	row := r.due[0]
+	r.due = r.due[1:]
+	dest[0], dest[1], dest[2], dest[3], dest[4] = int64(row.ID), row.Channel, row.Status, int64(row.Attempts), row.NextAttemptAt
+	return nil
+}
+
+func outboxRow(id uint, status string, attempts int, next time.Time) models.NotificationOutbox {
+	return models.NotificationOutbox{ID: id, Channel: "fake", Status: status, Attempts: attempts, NextAttemptAt: next}
+}
+
+func TestClaimDue(t *testing.T) {
+	cfg := testConfig()
+	now := time.Now()
+	f, db := newFakeOutboxDB(t,
+		outboxRow(1, StatusPending, 0, now.Add(-time.Minute)),
+		outboxRow(2, StatusPending, 1, now.Add(time.Minute)),           // belum jatuh tempo
+		outboxRow(3, StatusProcessing, 1, now.Add(-time.Second)),       // lease habis, worker dianggap macet
+		outboxRow(4, StatusProcessing, 2, now.Add(cfg.Lease/2)),        // masih dikirim worker lain
+		outboxRow(5, StatusSent, 1, now.Add(-time.Hour)),               // sudah terkirim
+		outboxRow(6, StatusDead, cfg.MaxAttempts, now.Add(-time.Hour)), // dead tidak diambil otomatis
+	)
+
+	claimed := claimDue(db, cfg, now, 10)
+	got := map[uint]int{}
+	for _, item := range claimed {
+		got[item.ID] = item.Attempts
+	}
+	if len(got) != 2 || got[1] != 1 || got[3] != 2 {
+		t.Fatalf("notifikasi terklaim (id -> attempts) = %v, mau map[1:1 3:2]", got)
+	}
+
+	for _, id := range []uint{1, 3} {
+		row := f.rows[id]
+		if row.Status != StatusProcessing || !row.NextAttemptAt.Equal(now.Add(cfg.Lease)) {
+			t.Errorf("notifikasi %d setelah diklaim = %s / %v, mau processing / now+Lease", id, row.Status, row.NextAttemptAt)
+		}
+	}
+	untouched := map[uint]int{2: 1, 4: 2, 5: 1, 6: cfg.MaxAttempts}
+	for id, attempts := range untouched {
+		if row := f.rows[id]; row.Attempts != attempts {
+			t.Errorf("notifikasi %d tidak boleh diklaim, attempts = %d", id, row.Attempts)
+		}
+	}
+
+	// lease baru belum habis, jadi pengecekan berikutnya tidak mengambil ulang
+	if again := claimDue(db, cfg, now.Add(time.Second), 10); len(again) != 0 {
+		t.Errorf("notifikasi yang baru diklaim diambil ulang: %+v", again)
+	}
+	// setelah lease habis (worker macet) notifikasi diambil ulang dengan percobaan baru
+	claimDue(db, cfg, now.Add(cfg.Lease), 10)
+	if f.rows[1].Attempts != 2 || f.rows[3].Attempts != 3 {
+		t.Errorf("reclaim setelah lease habis: attempts notifikasi 1 & 3 = %d & %d, mau 2 & 3",
+			f.rows[1].Attempts, f.rows[3].Attempts)
+	}
+}
+
+func TestClaimDueLostRace(t *testing.T) {
+	cfg := testConfig()
+	now := time.Now()
+	f, db := newFakeOutboxDB(t,
+		outboxRow(1, StatusPending, 0, now.Add(-time.Minute)),
+		outboxRow(2, StatusPending, 0, now.Add(-time.Second)),
+	)
+	// instance lain mengklaim notifikasi 1 di antara SELECT dan UPDATE
+	f.beforeClaim = func(row *models.NotificationOutbox) {
+		if row.ID == 1 && row.Status == StatusPending {
+			row.Status, row.Attempts, row.NextAttemptAt = StatusProcessing, 1, now.Add(cfg.Lease)
+		}
+	}
+
+	claimed := claimDue(db, cfg, now, 10)
+	if len(claimed) != 1 || claimed[0].ID != 2 {
+		t.Fatalf("terklaim = %+v, mau hanya notifikasi 2", claimed)
+	}
+	if f.rows[1].Attempts != 1 {
+		t.Errorf("notifikasi milik instance lain ikut diubah, attempts = %d", f.rows[1].Attempts)
+	}
+}
+
+func TestDispatcherClaimsOnlyForIdleWorkers(t *testing.T) {
+	cfg := testConfig()
+	cfg.Workers = 3
+	cfg.BatchSize = 2
+	now := time.Now()
+
+	var rows []models.NotificationOutbox
+	for id := uint(1); id <= 5; id++ {
+		rows = append(rows, outboxRow(id, StatusPending, 0, now.Add(-time.Duration(id)*time.Second)))
+	}
+	f, db := newFakeOutboxDB(t, rows...)
+
+	idle := make(chan struct{}, cfg.Workers)
+	jobs := make(chan models.NotificationOutbox, len(rows)) // worker palsu: hanya menampung job
+	d := &dispatcher{
+		cfg:   cfg,
+		idle:  idle,
+		jobs:  jobs,
+		claim: func(limit int) []models.NotificationOutbox { return claimDue(db, cfg, now, limit) },
+	}
+	markIdle := func(n int) {
+		for i := 0; i < n; i++ {
+			idle <- struct{}{}
+		}
+	}
+
+	steps := []struct {
+		name  string
+		idle  int // worker yang selesai sebelum dispatch
+		limit int // LIMIT query yang diharapkan, 0 = tidak mengambil
+		jobs  int // total job yang sudah dibagikan
+		free  int // worker menganggur tersisa
+	}{
+		{"3 worker menganggur, dibatasi BatchSize", 3, 2, 2, 1},
+		{"sisa 1 worker", 0, 1, 3, 0},
+		{"semua worker sibuk", 0, 0, 3, 0},
+		{"1 worker selesai", 1, 1, 4, 0},
+		{"notifikasi jatuh tempo tinggal 1", 3, 2, 5, 2},
+		{"antrean kosong", 0, 2, 5, 2},
+	}
+	for _, step := range steps {
+		markIdle(step.idle)
+		before := len(f.limits)
+		d.dispatch()
+
+		switch {
+		case step.limit == 0 && len(f.limits) != before:
+			t.Errorf("%s: outbox tetap diambil dengan LIMIT %d", step.name, f.limits[len(f.limits)-1])
+		case step.limit != 0 && (len(f.limits) != before+1 || f.limits[before] != step.limit):
+			t.Errorf("%s: LIMIT = %v, mau %d", step.name, f.limits[before:], step.limit)
+		}
+		if len(jobs) != step.jobs || d.free != step.free {
+			t.Errorf("%s: job = %d, worker menganggur = %d, mau %d & %d", step.name, len(jobs), d.free, step.jobs, step.free)
+		}
+	}
+
+	// job dibagikan sesuai urutan jatuh tempo
+	for want := uint(5); want >= 1; want-- {
+		if item := <-jobs; item.ID != want {
+			t.Errorf("job = notifikasi %d, mau %d", item.ID, want)
+		}
+	}
+}
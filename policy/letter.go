@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"fmt"
+
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"gorm.io/gorm"
+)
+
+// ===============================
+// Role & aksi
+// ===============================
+
+const (
+	RoleAdmin    = "admin"
+	RoleReviewer = "reviewer"
+	RoleUser     = "user"
+)
+
+// Action adalah aksi yang bisa dilakukan terhadap surat
+type Action string
+
+const (
+	ActionCreate   Action = "create"   // membuat pengajuan surat
+	ActionView     Action = "view"     // melihat detail, riwayat, PDF & lampiran
+	ActionEdit     Action = "edit"     // mengubah jenis & isi surat
+	ActionTransfer Action = "transfer" // memindahkan surat ke pemilik lain
+	ActionDelete   Action = "delete"   // menghapus surat
+	ActionReview   Action = "review"   // mengubah status (menerima / menolak)
+	ActionSubmit   Action = "submit"   // mengirim draft ke reviewer
+	ActionAttach   Action = "attach"   // menambah lampiran
+	ActionCancel   Action = "cancel"   // menarik pengajuan oleh pemohon
+	ActionResubmit Action = "resubmit" // merevisi & mengajukan ulang surat yang ditolak
+	ActionAssign   Action = "assign"   // menugaskan surat ke reviewer tertentu
+	ActionComment  Action = "comment"  // menulis & membaca komentar surat
+	ActionStats    Action = "stats"    // melihat statistik seluruh surat (letter nil)
+
+	// ActionCommentInternal menulis & membaca komentar internal (tidak terlihat pemohon)
+	ActionCommentInternal Action = "comment_internal"
+)
+
+// Actor adalah user yang sedang login (dari token JWT)
+type Actor struct {
+	ID   uint
+	Role string
+}
+
+// IsOwner mengecek apakah actor adalah pemilik surat
+func (a Actor) IsOwner(letter *models.Letter) bool {
+	return letter != nil && a.Role == RoleUser && letter.UserID == a.ID
+}
+
+// DeniedError dikembalikan kalau actor tidak boleh melakukan aksi
+type DeniedError struct {
+	Action Action
+	Reason string
+}
+
+func (e *DeniedError) Error() string {
+	return e.Reason
+}
+
+func deny(action Action, reason string) error {
+	return &DeniedError{Action: action, Reason: reason}
+}
+
+// ===============================
+// Aturan akses surat
+// ===============================
+
+// Authorize adalah satu-satunya tempat aturan akses surat per role.
+// Mengembalikan nil kalau boleh, *DeniedError kalau tidak.
+// letter boleh nil untuk ActionCreate.
+func Authorize(a Actor, action Action, letter *models.Letter) error {
+	switch a.Role {
+	case RoleAdmin:
+		return nil
+
+	case RoleReviewer:
+		switch action {
+		case ActionView, ActionReview, ActionComment, ActionCommentInternal, ActionStats:
+			return nil
+		case ActionCreate:
+			return deny(action, "Reviewer tidak boleh membuat surat")
+		case ActionEdit:
+			return deny(action, "Reviewer hanya bisa mengubah status surat")
+		default:
+			return deny(action, "Reviewer hanya bisa menerima atau menolak pengajuan surat!")
+		}
+
+	case RoleUser:
+		switch action {
+		case ActionCreate:
+			return nil
+		case ActionView, ActionComment:
+			if a.IsOwner(letter) {
+				return nil
+			}
+			return deny(action, "Tidak boleh mengakses surat milik user lain")
+		case ActionCommentInternal:
+			return deny(action, "Komentar internal hanya untuk reviewer & admin")
+		case ActionEdit:
+			if !a.IsOwner(letter) {
+				return deny(action, "Hanya pemilik surat yang bisa mengubah surat")
+			}
+			if letter.Status != workflow.StatusDraft {
+				return deny(action, "Hanya draft yang bisa diubah pemohon")
+			}
+			return nil
+		case ActionResubmit:
+			if !a.IsOwner(letter) {
+				return deny(action, "Hanya pemilik surat yang bisa mengajukan ulang")
+			}
+			return nil
+		case ActionSubmit, ActionAttach, ActionCancel:
+			if !a.IsOwner(letter) {
+				return deny(action, "Hanya pemilik surat yang bisa melakukan aksi ini")
+			}
+			if letter.Status != workflow.StatusDraft && !workflow.IsPending(letter.Status) {
+				return deny(action, "Surat yang sudah diputuskan tidak bisa diubah")
+			}
+			return nil
+		default:
+			return deny(action, "User hanya bisa mengajukan surat!")
+		}
+	}
+
+	return deny(action, "Role tidak dikenali")
+}
+
+// reviewerStatuses adalah status yang boleh dipasang reviewer
+var reviewerStatuses = map[string]bool{
+	workflow.StatusInReview: true,
+	workflow.StatusAccepted: true,
+	workflow.StatusRejected: true,
+}
+
+// AuthorizeStatus mengecek status tujuan yang boleh dipasang actor saat review.
+// Admin boleh semua status (tetap lewat state machine), reviewer hanya keputusan review.
+// Status submitted hanya lewat submit / resubmit supaya validasi isian, revisi,
+// penugasan reviewer & SLA tidak terlewat, termasuk untuk admin.
+func AuthorizeStatus(a Actor, status string) error {
+	if status == workflow.StatusSubmitted {
+		return deny(ActionReview, "Surat hanya bisa dikirim lewat endpoint submit atau resubmit")
+	}
+	if a.Role == RoleAdmin {
+		return nil
+	}
+	if a.Role == RoleReviewer && reviewerStatuses[status] {
+		return nil
+	}
+	return deny(ActionReview, "Status tidak valid untuk reviewer")
+}
+
+// AuthorizeStep mengecek apakah actor adalah approver tahap persetujuan tersebut.
+// Admin boleh memutuskan tahap mana pun.
+func AuthorizeStep(a Actor, step models.ApprovalStep) error {
+	if a.Role == RoleAdmin {
+		return nil
+	}
+	if step.UserID != nil {
+		if *step.UserID == a.ID {
+			return nil
+		}
+		return deny(ActionReview, fmt.Sprintf("Tahap '%s' harus diputuskan oleh approver yang ditunjuk", step.Name))
+	}
+	if step.Role == a.Role {
+		return nil
+	}
+	return deny(ActionReview, fmt.Sprintf("Tahap '%s' harus diputuskan oleh role %s", step.Name, step.Role))
+}
+
+// AuthorizeAssignee mengecek bahwa reviewer hanya memutuskan surat yang ditugaskan kepadanya.
+// Surat tanpa penugasan boleh diputuskan reviewer mana pun.
+func AuthorizeAssignee(a Actor, letter *models.Letter) error {
+	if a.Role != RoleReviewer || letter.AssignedReviewerID == nil || *letter.AssignedReviewerID == a.ID {
+		return nil
+	}
+	return deny(ActionReview, "Surat ini ditugaskan ke reviewer lain")
+}
+
+// AuthorizeComment mengecek apakah actor boleh menghapus komentar surat:
+// hanya penulis komentar, atau admin.
+func AuthorizeComment(a Actor, comment models.LetterComment) error {
+	if a.Role == RoleAdmin || (a.ID != 0 && comment.UserID == a.ID) {
+		return nil
+	}
+	return deny(ActionComment, "Hanya penulis komentar yang bisa menghapusnya")
+}
+
+// LetterScope membatasi query daftar surat sesuai hak lihat actor
+func LetterScope(a Actor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch a.Role {
+		case RoleAdmin, RoleReviewer:
+			return db
+		case RoleUser:
+			return db.Where("letters.user_id = ?", a.ID)
+		}
+		return db.Where("1 = 0")
+	}
+}
@@ -0,0 +1,317 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"sanbercode-golang-batch-70-final-project/models"
+	"sanbercode-golang-batch-70-final-project/workflow"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+const (
+	ownerID    uint = 10
+	otherID    uint = 11
+	reviewerID uint = 20
+)
+
+var (
+	admin    = Actor{ID: 1, Role: RoleAdmin}
+	reviewer = Actor{ID: reviewerID, Role: RoleReviewer}
+	owner    = Actor{ID: ownerID, Role: RoleUser}
+	nonOwner = Actor{ID: otherID, Role: RoleUser}
+	unknown  = Actor{ID: 99, Role: "guest"}
+)
+
+var allActions = []Action{
+	ActionCreate, ActionView, ActionEdit, ActionTransfer, ActionDelete, ActionReview,
+	ActionSubmit, ActionAttach, ActionCancel, ActionResubmit, ActionAssign,
+	ActionComment, ActionStats, ActionCommentInternal,
+}
+
+var allStatuses = []string{
+	workflow.StatusDraft, workflow.StatusSubmitted, workflow.StatusInReview,
+	workflow.StatusAccepted, workflow.StatusRejected, workflow.StatusCancelled, workflow.StatusArchived,
+}
+
+// every berarti aksi boleh di semua status surat
+var every = allStatuses
+
+// open adalah status saat pemohon masih boleh mengubah pengajuannya
+var open = []string{workflow.StatusDraft, workflow.StatusSubmitted, workflow.StatusInReview}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor Actor
+		// allowed berisi status surat (milik ownerID) tempat aksi diizinkan;
+		// aksi yang tidak ada di map ditolak di semua status
+		allowed map[Action][]string
+	}{
+		{
+			name:  "admin",
+			actor: admin,
+			allowed: map[Action][]string{
+				ActionCreate: every, ActionView: every, ActionEdit: every, ActionTransfer: every,
+				ActionDelete: every, ActionReview: every, ActionSubmit: every, ActionAttach: every,
+				ActionCancel: every, ActionResubmit: every, ActionAssign: every, ActionComment: every,
+				ActionStats: every, ActionCommentInternal: every,
+			},
+		},
+		{
+			name:  "reviewer",
+			actor: reviewer,
+			allowed: map[Action][]string{
+				ActionView: every, ActionReview: every, ActionComment: every,
+				ActionCommentInternal: every, ActionStats: every,
+			},
+		},
+		{
+			name:  "pemilik surat",
+			actor: owner,
+			allowed: map[Action][]string{
+				ActionCreate:   every,
+				ActionView:     every,
+				ActionComment:  every,
+				ActionResubmit: every, // status rejected dicek state machine
+				ActionEdit:     {workflow.StatusDraft},
+				ActionSubmit:   open,
+				ActionAttach:   open,
+				ActionCancel:   open,
+			},
+		},
+		{
+			name:  "user lain",
+			actor: nonOwner,
+			allowed: map[Action][]string{
+				ActionCreate: every,
+			},
+		},
+		{
+			name:    "role tidak dikenal",
+			actor:   unknown,
+			allowed: map[Action][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, action := range allActions {
+			allowedIn := map[string]bool{}
+			for _, status := range tt.allowed[action] {
+				allowedIn[status] = true
+			}
+
+			for _, status := range allStatuses {
+				letter := &models.Letter{ID: 5, UserID: ownerID, Status: status}
+				err := Authorize(tt.actor, action, letter)
+
+				if allowedIn[status] {
+					if err != nil {
+						t.Errorf("%s %s surat %s: ditolak (%v), seharusnya boleh", tt.name, action, status, err)
+					}
+					continue
+				}
+				var denied *DeniedError
+				if !errors.As(err, &denied) {
+					t.Errorf("%s %s surat %s: err = %v, seharusnya *DeniedError", tt.name, action, status, err)
+					continue
+				}
+				if denied.Action != action || denied.Reason == "" {
+					t.Errorf("%s %s surat %s: DeniedError = %+v", tt.name, action, status, denied)
+				}
+			}
+		}
+	}
+}
+
+func TestAuthorizeWithoutLetter(t *testing.T) {
+	tests := []struct {
+		actor   Actor
+		action  Action
+		allowed bool
+	}{
+		{admin, ActionCreate, true},
+		{admin, ActionStats, true},
+		{reviewer, ActionStats, true},
+		{reviewer, ActionReview, true},
+		{reviewer, ActionCreate, false},
+		{owner, ActionCreate, true},
+		{owner, ActionStats, false},
+		{owner, ActionReview, false},
+		{owner, ActionView, false},
+		{owner, ActionEdit, false},
+		{owner, ActionSubmit, false},
+		{unknown, ActionCreate, false},
+	}
+	for _, tt := range tests {
+		err := Authorize(tt.actor, tt.action, nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s %s tanpa surat: err = %v, boleh = %v", tt.actor.Role, tt.action, err, tt.allowed)
+		}
+	}
+}
+
+func TestAuthorizeStatus(t *testing.T) {
+	targets := append([]string{}, allStatuses...)
+	targets = append(targets, "unknown")
+
+	tests := []struct {
+		name    string
+		actor   Actor
+		allowed []string
+	}{
+		{
+			name:  "admin",
+			actor: admin,
+			allowed: []string{
+				workflow.StatusDraft, workflow.StatusInReview, workflow.StatusAccepted, workflow.StatusRejected,
+				workflow.StatusCancelled, workflow.StatusArchived, "unknown", // status asing ditolak state machine
+			},
+		},
+		{
+			name:    "reviewer",
+			actor:   reviewer,
+			allowed: []string{workflow.StatusInReview, workflow.StatusAccepted, workflow.StatusRejected},
+		},
+		{name: "pemilik surat", actor: owner},
+		{name: "user lain", actor: nonOwner},
+		{name: "role tidak dikenal", actor: unknown},
+	}
+
+	for _, tt := range tests {
+		allowed := map[string]bool{}
+		for _, status := range tt.allowed {
+			allowed[status] = true
+		}
+		for _, status := range targets {
+			err := AuthorizeStatus(tt.actor, status)
+			if allowed[status] {
+				if err != nil {
+					t.Errorf("%s memasang status %s: ditolak (%v), seharusnya boleh", tt.name, status, err)
+				}
+				continue
+			}
+			var denied *DeniedError
+			if !errors.As(err, &denied) || denied.Action != ActionReview {
+				t.Errorf("%s memasang status %s: err = %v, seharusnya DeniedError review", tt.name, status, err)
+			}
+		}
+	}
+}
+
+func TestAuthorizeAssignee(t *testing.T) {
+	assigned := reviewerID
+	other := reviewerID + 1
+
+	tests := []struct {
+		name     string
+		actor    Actor
+		assignee *uint
+		allowed  bool
+	}{
+		{"reviewer, surat belum ditugaskan", reviewer, nil, true},
+		{"reviewer yang ditugaskan", reviewer, &assigned, true},
+		{"reviewer lain", reviewer, &other, false},
+		{"admin, surat milik reviewer lain", admin, &other, true},
+		{"admin, surat belum ditugaskan", admin, nil, true},
+		{"pemilik surat", owner, &assigned, true}, // akses pemohon dicek Authorize
+		{"user lain", nonOwner, &assigned, true},
+		{"role tidak dikenal", unknown, &assigned, true},
+	}
+	for _, tt := range tests {
+		for _, status := range allStatuses {
+			letter := &models.Letter{ID: 5, UserID: ownerID, Status: status, AssignedReviewerID: tt.assignee}
+			err := AuthorizeAssignee(tt.actor, letter)
+			if (err == nil) != tt.allowed {
+				t.Errorf("%s, surat %s: err = %v, boleh = %v", tt.name, status, err, tt.allowed)
+			}
+		}
+	}
+}
+
+func TestAuthorizeStep(t *testing.T) {
+	approver := reviewerID
+	tests := []struct {
+		name    string
+		actor   Actor
+		step    models.ApprovalStep
+		allowed bool
+	}{
+		{"admin, tahap role reviewer", admin, models.ApprovalStep{Name: "Kaprodi", Role: RoleReviewer}, true},
+		{"admin, tahap user tertentu", admin, models.ApprovalStep{Name: "Dekan", UserID: &approver}, true},
+		{"reviewer, tahap role reviewer", reviewer, models.ApprovalStep{Name: "Kaprodi", Role: RoleReviewer}, true},
+		{"reviewer, tahap role admin", reviewer, models.ApprovalStep{Name: "TU", Role: RoleAdmin}, false},
+		{"reviewer yang ditunjuk", reviewer, models.ApprovalStep{Name: "Dekan", UserID: &approver}, true},
+		{"reviewer lain", Actor{ID: reviewerID + 1, Role: RoleReviewer}, models.ApprovalStep{Name: "Dekan", UserID: &approver}, false},
+		{"user", owner, models.ApprovalStep{Name: "Kaprodi", Role: RoleReviewer}, false},
+	}
+	for _, tt := range tests {
+		err := AuthorizeStep(tt.actor, tt.step)
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s: err = %v, boleh = %v", tt.name, err, tt.allowed)
+		}
+	}
+}
+
+func TestAuthorizeComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   Actor
+		author  uint
+		allowed bool
+	}{
+		{"penulis komentar", owner, ownerID, true},
+		{"user lain", nonOwner, ownerID, false},
+		{"reviewer penulis komentar", reviewer, reviewerID, true},
+		{"reviewer, komentar pemohon", reviewer, ownerID, false},
+		{"admin, komentar user lain", admin, ownerID, true},
+		{"role tidak dikenal", unknown, ownerID, false},
+	}
+	for _, tt := range tests {
+		err := AuthorizeComment(tt.actor, models.LetterComment{ID: 3, LetterID: 5, UserID: tt.author})
+		if tt.allowed {
+			if err != nil {
+				t.Errorf("%s: ditolak (%v), seharusnya boleh", tt.name, err)
+			}
+			continue
+		}
+		var denied *DeniedError
+		if !errors.As(err, &denied) || denied.Action != ActionComment || denied.Reason == "" {
+			t.Errorf("%s: err = %v, seharusnya DeniedError comment", tt.name, err)
+		}
+	}
+}
+
+func TestLetterScope(t *testing.T) {
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/x", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		actor Actor
+		where string // potongan WHERE yang diharapkan, kosong = tanpa filter
+	}{
+		{"admin", admin, ""},
+		{"reviewer", reviewer, ""},
+		{"pemilik surat", owner, "WHERE letters.user_id = 10"},
+		{"user lain", nonOwner, "WHERE letters.user_id = 11"},
+		{"role tidak dikenal", unknown, "WHERE 1 = 0"},
+	}
+	for _, tt := range tests {
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Scopes(LetterScope(tt.actor)).Find(&[]models.Letter{})
+		})
+		switch {
+		case tt.where == "" && strings.Contains(sql, "WHERE"):
+			t.Errorf("%s: query seharusnya tanpa filter, dapat %s", tt.name, sql)
+		case tt.where != "" && !strings.Contains(sql, tt.where):
+			t.Errorf("%s: query seharusnya mengandung %q, dapat %s", tt.name, tt.where, sql)
+		}
+	}
+}
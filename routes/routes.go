@@ -28,6 +28,11 @@ func SetupRouter() *gin.Engine {
         api.POST("/users/register", controllers.Register)
         api.POST("/users/login", controllers.Login)
 
+        // ===============================
+        // VERIFIKASI SURAT (publik, dari QR code)
+        // ===============================
+        api.GET("/verify/:token", controllers.VerifyLetter)
+
         // ===============================
         // LETTERS (user & admin)
         // ===============================
@@ -36,11 +41,35 @@ func SetupRouter() *gin.Engine {
         {
             letters.POST("", controllers.CreateLetter)
             letters.GET("", controllers.GetLetters)
+            letters.GET("/export", controllers.ExportLetters)
+            letters.POST("/bulk-decision", controllers.BulkDecideLetters)
             letters.GET("/:id", controllers.GetLetterByID)
+            letters.POST("/:id/submit", controllers.SubmitLetter)
+            letters.POST("/:id/cancel", controllers.CancelLetter)
+            letters.POST("/:id/resubmit", controllers.ResubmitLetter)
+            letters.PUT("/:id/assignee", controllers.AssignLetter)
+            letters.GET("/:id/revisions", controllers.GetLetterRevisions)
+            letters.GET("/:id/history", controllers.GetLetterHistory)
+            letters.GET("/:id/comments", controllers.GetLetterComments)
+            letters.POST("/:id/comments", controllers.CreateLetterComment)
+            letters.DELETE("/:id/comments/:comment_id", controllers.DeleteLetterComment)
+            letters.GET("/:id/pdf", controllers.GetLetterPDF)
+            letters.POST("/:id/attachments", controllers.UploadAttachments)
+            letters.GET("/:id/attachments", controllers.GetAttachments)
+            letters.GET("/:id/attachments/:attachment_id", controllers.DownloadAttachment)
             letters.PUT("/:id", controllers.UpdateLetter)
             letters.DELETE("/:id", controllers.DeleteLetter)
         }
 
+        // ===============================
+        // STATISTIK (admin & reviewer, dicek di policy)
+        // ===============================
+        stats := api.Group("/stats")
+        stats.Use(middlewares.AuthMiddleware(""))
+        {
+            stats.GET("/letters", controllers.LetterStats)
+        }
+
         // ===============================
         // ADMIN (khusus role admin)
         // ===============================
@@ -67,6 +96,8 @@ func SetupRouter() *gin.Engine {
             admin.GET("/letter_types/:id", controllers.GetLetterTypeByID)
             admin.PUT("/letter_types/:id", controllers.UpdateLetterType)
             admin.DELETE("/letter_types/:id", controllers.DeleteLetterType)
+            admin.GET("/letter_types/:id/reviewers", controllers.GetLetterTypeReviewers)
+            admin.PUT("/letter_types/:id/reviewers", controllers.SetLetterTypeReviewers)
 
             // Settings
             admin.POST("/settings", controllers.CreateSetting)
@@ -74,6 +105,12 @@ func SetupRouter() *gin.Engine {
             admin.GET("/settings/:id", controllers.GetSettingByID)
             admin.PUT("/settings/:id", controllers.UpdateSetting)
             admin.DELETE("/settings/:id", controllers.DeleteSetting)
+
+            // Outbox notifikasi
+            admin.GET("/notifications/outbox", controllers.GetNotificationOutbox)
+            admin.POST("/notifications/outbox/retry", controllers.RetryDeadNotifications)
+            admin.POST("/notifications/outbox/:id/retry", controllers.RetryNotification)
+            admin.GET("/notifications/deliveries", controllers.GetNotificationDeliveries)
         }
     }
 
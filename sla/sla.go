@@ -0,0 +1,97 @@
+package sla
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ===============================
+// Jam kerja
+// ===============================
+
+// Calendar adalah jam kerja yang dipakai untuk menghitung tenggat review.
+// Hari kerja Senin - Jumat, jam kerja [Start, End) dalam zona waktu Location.
+type Calendar struct {
+	Start    int // jam mulai kerja, misal 8
+	End      int // jam selesai kerja, misal 16
+	Location *time.Location
+}
+
+// DefaultCalendar membaca jam kerja dari env SLA_WORK_START & SLA_WORK_END (default 08.00 - 16.00).
+// Kalau jam selesai tidak setelah jam mulai (misal SLA_WORK_START=16 tanpa SLA_WORK_END),
+// dipakai default supaya perhitungan tenggat tidak berputar tanpa akhir.
+func DefaultCalendar() Calendar {
+	cal := Calendar{Start: 8, End: 16, Location: time.Local}
+	if v, err := strconv.Atoi(os.Getenv("SLA_WORK_START")); err == nil && v >= 0 && v < 24 {
+		cal.Start = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("SLA_WORK_END")); err == nil && v > 0 && v <= 24 {
+		cal.End = v
+	}
+	if cal.End <= cal.Start {
+		log.Printf("Jam kerja SLA tidak valid (%d - %d), memakai 8 - 16", cal.Start, cal.End)
+		cal.Start, cal.End = 8, 16
+	}
+	return cal
+}
+
+// Validate memeriksa SLA & batas eskalasi milik jenis surat (dalam jam kerja).
+// 0 berarti tidak ada SLA / tidak ada eskalasi.
+func Validate(reviewHours, escalationHours int) error {
+	if reviewHours < 0 || escalationHours < 0 {
+		return errors.New("SLA & batas eskalasi tidak boleh negatif")
+	}
+	if escalationHours > 0 && reviewHours == 0 {
+		return errors.New("Batas eskalasi butuh SLA review")
+	}
+	if escalationHours > 0 && escalationHours <= reviewHours {
+		return errors.New("Batas eskalasi harus lebih besar dari SLA review")
+	}
+	return nil
+}
+
+// isWorkday mengecek apakah tanggal termasuk hari kerja
+func isWorkday(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+func (c Calendar) at(day time.Time, hour int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, c.Location)
+}
+
+// nextWorkingTime memajukan t ke saat kerja terdekat (t sendiri kalau sedang jam kerja)
+func (c Calendar) nextWorkingTime(t time.Time) time.Time {
+	t = t.In(c.Location)
+	for {
+		if isWorkday(t) {
+			if t.Before(c.at(t, c.Start)) {
+				return c.at(t, c.Start)
+			}
+			if t.Before(c.at(t, c.End)) {
+				return t
+			}
+		}
+		t = c.at(t.AddDate(0, 0, 1), c.Start)
+	}
+}
+
+// Add menghitung waktu setelah sejumlah jam kerja sejak t.
+// Contoh: Jumat 15.00 + 2 jam kerja (08.00 - 16.00) = Senin 09.00.
+func (c Calendar) Add(t time.Time, hours int) time.Time {
+	if hours <= 0 {
+		return t
+	}
+	remaining := time.Duration(hours) * time.Hour
+	t = c.nextWorkingTime(t)
+	for {
+		available := c.at(t, c.End).Sub(t)
+		if remaining <= available {
+			return t.Add(remaining)
+		}
+		remaining -= available
+		t = c.nextWorkingTime(c.at(t, c.End))
+	}
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "ap-southeast-1"
+	testBucket    = "surat"
+)
+
+// fakeS3 adalah bucket S3 di memori yang memverifikasi tanda tangan SigV4 setiap request
+type fakeS3 struct {
+	t       *testing.T
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	paths   []string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{t: t, objects: map[string][]byte{}, types: map[string]string{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := verifySignature(r); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, r.URL.EscapedPath())
+
+	prefix := "/" + testBucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if int64(len(body)) != r.ContentLength {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verifySignature menghitung ulang tanda tangan AWS Signature V4 dari request yang diterima
+func verifySignature(r *http.Request) error {
+	amzDate := r.Header.Get("X-Amz-Date")
+	if _, err := time.Parse("20060102T150405Z", amzDate); err != nil {
+		return fmt.Errorf("X-Amz-Date tidak valid: %q", amzDate)
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	switch {
+	case r.Method == http.MethodPut && payloadHash != "UNSIGNED-PAYLOAD":
+		return fmt.Errorf("PUT harus UNSIGNED-PAYLOAD, dapat %q", payloadHash)
+	case r.Method != http.MethodPut && payloadHash != sha256Hex(""):
+		return fmt.Errorf("%s harus memakai hash payload kosong, dapat %q", r.Method, payloadHash)
+	}
+
+	date := amzDate[:8]
+	scope := date + "/" + testRegion + "/s3/aws4_request"
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonical := r.Method + "\n" +
+		r.URL.EscapedPath() + "\n" +
+		"\n" +
+		"host:" + r.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n" +
+		"\n" +
+		signedHeaders + "\n" +
+		payloadHash
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex(canonical)
+
+	signingKey := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{date, testRegion, "s3", "aws4_request"} {
+		signingKey = hmacSum(signingKey, part)
+	}
+	want := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		testAccessKey, scope, signedHeaders, hex.EncodeToString(hmacSum(signingKey, stringToSign)))
+	if got := r.Header.Get("Authorization"); got != want {
+		return fmt.Errorf("SignatureDoesNotMatch\ngot:  %s\nwant: %s", got, want)
+	}
+	return nil
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSum(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func newTestS3(t *testing.T, endpoint, secret string) *S3 {
+	t.Helper()
+	s, err := NewS3(S3Config{
+		Endpoint:  endpoint,
+		Region:    testRegion,
+		Bucket:    testBucket,
+		AccessKey: testAccessKey,
+		SecretKey: secret,
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	return s
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s := newTestS3(t, srv.URL+"/", testSecretKey)
+	ctx := context.Background()
+
+	keys := []string{
+		"letters/1/3f2a9c.pdf",
+		"letters/2/nama file (final)+v2.png", // karakter yang wajib di-encode
+	}
+	for _, key := range keys {
+		content := "isi " + key
+		if err := s.Put(ctx, key, strings.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+		if got := string(fake.objects[key]); got != content {
+			t.Errorf("Put(%q) menyimpan %q, mau %q", key, got, content)
+		}
+		if got := fake.types[key]; got != "application/pdf" {
+			t.Errorf("Put(%q) Content-Type = %q", key, got)
+		}
+
+		rc, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q): %v", key, err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(body) != content {
+			t.Errorf("Get(%q) = %q, mau %q", key, body, content)
+		}
+
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete(%q): %v", key, err)
+		}
+		if _, ok := fake.objects[key]; ok {
+			t.Errorf("Delete(%q) tidak menghapus object", key)
+		}
+	}
+
+	wantPath := "/surat/letters/2/nama%20file%20%28final%29%2Bv2.png"
+	found := false
+	for _, p := range fake.paths {
+		found = found || p == wantPath
+	}
+	if !found {
+		t.Errorf("path ter-encode %q tidak pernah diminta, dapat %v", wantPath, fake.paths)
+	}
+}
+
+func TestS3MissingObject(t *testing.T) {
+	_, srv := newFakeS3(t)
+	s := newTestS3(t, srv.URL, testSecretKey)
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "letters/9/tidak-ada.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get object yang tidak ada: err = %v, mau ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "letters/9/tidak-ada.pdf"); err != nil {
+		t.Errorf("Delete object yang tidak ada harus nil, dapat %v", err)
+	}
+}
+
+func TestS3WrongSecretRejected(t *testing.T) {
+	_, srv := newFakeS3(t)
+	s := newTestS3(t, srv.URL, "secret-yang-salah")
+
+	err := s.Put(context.Background(), "letters/1/a.pdf", strings.NewReader("x"), 1, "application/pdf")
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Fatalf("Put dengan secret salah: err = %v, mau status 403", err)
+	}
+}
+
+func TestNewS3RequiresConfig(t *testing.T) {
+	if _, err := NewS3(S3Config{Endpoint: "http://localhost:9000", Bucket: testBucket}); err == nil {
+		t.Fatal("NewS3 tanpa access key harus gagal")
+	}
+	s, err := NewS3(S3Config{Endpoint: "http://localhost:9000", Bucket: testBucket, AccessKey: "a", SecretKey: "b"})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	if s.cfg.Region != "us-east-1" {
+		t.Errorf("region default = %q, mau us-east-1", s.cfg.Region)
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Storage adalah backend penyimpanan file lampiran surat
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ErrNotFound dikembalikan kalau file dengan key tersebut tidak ada
+var ErrNotFound = errors.New("file tidak ditemukan di storage")
+
+// Files adalah storage yang dipakai aplikasi (bisa diganti saat testing)
+var Files Storage
+
+// Setup memilih backend storage dari env STORAGE_DRIVER (local / s3)
+func Setup() {
+	s, err := FromEnv()
+	if err != nil {
+		log.Fatal("Failed to setup storage:", err)
+	}
+	Files = s
+}
+
+// FromEnv membuat storage sesuai konfigurasi env
+func FromEnv() (Storage, error) {
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocal(dir)
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER '%s' tidak dikenali (local/s3)", os.Getenv("STORAGE_DRIVER"))
+	}
+}
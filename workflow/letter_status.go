@@ -0,0 +1,87 @@
+package workflow
+
+import "fmt"
+
+// ===============================
+// Status surat
+// ===============================
+
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusInReview  = "in_review"
+	StatusAccepted  = "accepted"
+	StatusRejected  = "rejected"
+	StatusCancelled = "cancelled"
+	StatusArchived  = "archived"
+
+	// StatusDeleted hanya dipakai di riwayat status saat surat dihapus
+	StatusDeleted = "deleted"
+)
+
+// transitions adalah satu-satunya sumber kebenaran untuk perpindahan status surat.
+// Key = status asal, value = status tujuan yang diizinkan.
+var transitions = map[string][]string{
+	StatusDraft:     {StatusSubmitted, StatusCancelled},
+	StatusSubmitted: {StatusInReview, StatusCancelled},
+	StatusInReview:  {StatusAccepted, StatusRejected, StatusCancelled},
+	StatusAccepted:  {StatusCancelled, StatusArchived}, // cancelled = surat terbit dicabut (admin saja, dicek di policy)
+	StatusRejected:  {StatusSubmitted, StatusArchived}, // submitted = diajukan ulang setelah revisi
+	StatusCancelled: {StatusArchived},
+	StatusArchived:  {},
+}
+
+// TransitionError dikembalikan saat perpindahan status tidak diizinkan
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	if !IsValid(e.To) {
+		return fmt.Sprintf("Status '%s' tidak dikenali", e.To)
+	}
+	return fmt.Sprintf("Status surat tidak bisa diubah dari '%s' ke '%s'", e.From, e.To)
+}
+
+// IsValid mengecek apakah status termasuk status yang dikenal
+func IsValid(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// CanTransition mengecek apakah perpindahan from -> to diizinkan
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition memvalidasi perpindahan status dan mengembalikan *TransitionError kalau ilegal
+func Transition(from, to string) error {
+	if !CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// Path mengembalikan urutan status yang harus dilalui dari "from" ke "to".
+// Dipakai supaya reviewer bisa langsung memutuskan surat yang masih "submitted"
+// tanpa melompati tahap "in_review".
+func Path(from, to string) ([]string, error) {
+	if CanTransition(from, to) {
+		return []string{to}, nil
+	}
+	if from == StatusSubmitted && CanTransition(StatusInReview, to) {
+		return []string{StatusInReview, to}, nil
+	}
+	return nil, &TransitionError{From: from, To: to}
+}
+
+// IsPending mengecek apakah surat masih menunggu keputusan
+func IsPending(status string) bool {
+	return status == StatusSubmitted || status == StatusInReview
+}